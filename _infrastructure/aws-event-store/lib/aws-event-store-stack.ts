@@ -1,7 +1,7 @@
 import * as cdk from 'aws-cdk-lib';
 import {aws_dynamodb, CfnOutput} from 'aws-cdk-lib';
 import {Construct} from 'constructs';
-import {ProjectionType, StreamViewType, TableV2} from "aws-cdk-lib/aws-dynamodb";
+import {GlobalSecondaryIndexPropsV2, ProjectionType, StreamViewType, TableV2} from "aws-cdk-lib/aws-dynamodb";
 import {
     Architecture,
     Code,
@@ -18,6 +18,9 @@ import {LoggingProtocol, Topic} from "aws-cdk-lib/aws-sns";
 import {CfnPipe, CfnPipeProps} from "aws-cdk-lib/aws-pipes"
 import {LogGroup} from "aws-cdk-lib/aws-logs";
 import {esConfig} from "./esConfig";
+import {BlockPublicAccess, Bucket, BucketEncryption} from "aws-cdk-lib/aws-s3";
+import {Key} from "aws-cdk-lib/aws-kms";
+import {Secret} from "aws-cdk-lib/aws-secretsmanager";
 
 export class AwsEventStoreStack extends cdk.Stack {
     constructor(scope: Construct, id: string, props?: cdk.StackProps) {
@@ -27,13 +30,25 @@ export class AwsEventStoreStack extends cdk.Stack {
 
         const esLogs = new LogGroup(this, "EsLogs")
 
+        const esPayloadBucket = this.makePayloadBucket()
+
+        const esPayloadKey = this.makePayloadKey()
+
         const esLambda = this.makeLambdaFunction(esLogs)
+        // the new code is deployed once the table update is complete, i.e. the indexes it queries are active
+        esLambda.node.addDependency(esTable)
+        esPayloadBucket.grantReadWrite(esLambda)
+        esPayloadBucket.grantDelete(esLambda)
+        esPayloadKey.grant(esLambda, 'kms:GenerateDataKey', 'kms:Decrypt')
+
+        const esPageKeySecret = this.makePageKeySecret()
+        esPageKeySecret.grantRead(esLambda)
 
         const esUrl = this.addLambdaFunctionUrl(esLambda);
 
         const esSnsTopic = this.addNotifications(esTable, esLogs)
 
-        this.addSsmParameters(esTable, esUrl, esSnsTopic)
+        this.addSsmParameters(esTable, esPayloadBucket, esPayloadKey, esPageKeySecret, esUrl, esSnsTopic)
 
         this.makeStackOutputs(esTable, esLambda, esUrl, esSnsTopic)
     }
@@ -49,24 +64,95 @@ export class AwsEventStoreStack extends cdk.Stack {
                 type: aws_dynamodb.AttributeType.NUMBER,
             },
             removalPolicy: cdk.RemovalPolicy.RETAIN,
-            globalSecondaryIndexes: [
-                {
-                    indexName: 'StreamIndex',
-                    partitionKey: {
-                        name: 'StreamType',
-                        type: aws_dynamodb.AttributeType.STRING,
-                    },
-                    sortKey: {
-                        name: 'UpdatedAt',
-                        type: aws_dynamodb.AttributeType.STRING
-                    },
-                    projectionType: ProjectionType.INCLUDE,
-                    nonKeyAttributes: [
-                        'StreamRevision',
-                    ]
-                }
-            ],
-            dynamoStream: StreamViewType.NEW_IMAGE
+            globalSecondaryIndexes: this.makeTableIndexes(),
+            dynamoStream: StreamViewType.NEW_IMAGE,
+            timeToLiveAttribute: 'ExpiresAt',
+        })
+    }
+
+    // CloudFormation can neither change the projection of an existing index nor create or delete more than one index
+    // in a single update, so a deployed table gets the indexes one step per deploy with ES_TABLE_INDEX_STEP (see README).
+    // A new table is created with the indexes of the latest step at once.
+    private makeTableIndexes(): GlobalSecondaryIndexPropsV2[] {
+        // the index of the first release, replaced by StreamIndexV2, which projects the deletion, truncation and metadata
+        const streamIndex: GlobalSecondaryIndexPropsV2 = {
+            indexName: 'StreamIndex',
+            partitionKey: {
+                name: 'StreamType',
+                type: aws_dynamodb.AttributeType.STRING,
+            },
+            sortKey: {
+                name: 'UpdatedAt',
+                type: aws_dynamodb.AttributeType.STRING
+            },
+            projectionType: ProjectionType.INCLUDE,
+            nonKeyAttributes: [
+                'StreamRevision',
+            ]
+        }
+
+        const streamIndexV2: GlobalSecondaryIndexPropsV2 = {
+            ...streamIndex,
+            indexName: 'StreamIndexV2',
+            nonKeyAttributes: [
+                'StreamRevision',
+                'Deleted',
+                'TruncatedBefore',
+                'Metadata',
+            ]
+        }
+
+        // events only, stream records have no CategoryKey;
+        // events are then read from the table by their keys
+        const categoryIndex: GlobalSecondaryIndexPropsV2 = {
+            indexName: 'CategoryIndex',
+            partitionKey: {
+                name: 'StreamType',
+                type: aws_dynamodb.AttributeType.STRING,
+            },
+            sortKey: {
+                name: 'CategoryKey',
+                type: aws_dynamodb.AttributeType.STRING
+            },
+            projectionType: ProjectionType.KEYS_ONLY,
+        }
+
+        switch (esConfig.tableIndexStep) {
+            case 1:
+                return [streamIndex, streamIndexV2]
+            case 2:
+                return [streamIndex, streamIndexV2, categoryIndex]
+            default:
+                return [streamIndexV2, categoryIndex]
+        }
+    }
+
+    private makePayloadBucket() {
+        return new Bucket(this, 'EsPayloadBucket', {
+            encryption: BucketEncryption.S3_MANAGED,
+            blockPublicAccess: BlockPublicAccess.BLOCK_ALL,
+            enforceSSL: true,
+            removalPolicy: cdk.RemovalPolicy.RETAIN,
+        })
+    }
+
+    // wraps per-stream data keys, which are kept in the payload bucket;
+    // the bucket is not versioned, so that deleted data keys are gone for good
+    private makePayloadKey() {
+        return new Key(this, 'EsPayloadKey', {
+            enableKeyRotation: true,
+            removalPolicy: cdk.RemovalPolicy.RETAIN,
+        })
+    }
+
+    // signs next page keys, shared by all instances of the Lambda;
+    // it is read through the Secrets Manager reference of SSM, so it is kept out of the template
+    private makePageKeySecret() {
+        return new Secret(this, 'EsPageKeySecret', {
+            generateSecretString: {
+                passwordLength: 64,
+                excludePunctuation: true,
+            },
         })
     }
 
@@ -124,34 +210,59 @@ export class AwsEventStoreStack extends cdk.Stack {
         esLogs.grantWrite(notificationsRole)
         esTopic.grantPublish(notificationsRole)
 
-        new CfnPipe(this, 'EsPipe', {
-            roleArn: notificationsRole.roleArn,
+        // live and deleted streams go through separate pipes,
+        // since an input template cannot refer to the Deleted attribute when it is absent
+        this.addPipe('EsPipe', esTable, esTopic, esLogs, notificationsRole, `{
+                            "dynamodb": {
+                                "NewImage": {
+                                    "RecordType": {
+                                        "S": ["stream"]
+                                    },
+                                    "Deleted": [{ "exists": false }]
+                                }
+                            }
+                        }`, `{
+                    "StreamId": <$.dynamodb.Keys.PK.S>,
+                    "StreamType": <$.dynamodb.NewImage.StreamType.S>,
+                    "StreamRevision": <$.dynamodb.NewImage.StreamRevision.N>
+                }`)
+
+        this.addPipe('EsDeletionPipe', esTable, esTopic, esLogs, notificationsRole, `{
+                            "dynamodb": {
+                                "NewImage": {
+                                    "RecordType": {
+                                        "S": ["stream"]
+                                    },
+                                    "Deleted": {
+                                        "S": [{ "exists": true }]
+                                    }
+                                }
+                            }
+                        }`, `{
+                    "StreamId": <$.dynamodb.Keys.PK.S>,
+                    "StreamType": <$.dynamodb.NewImage.StreamType.S>,
+                    "StreamRevision": <$.dynamodb.NewImage.StreamRevision.N>,
+                    "Deleted": <$.dynamodb.NewImage.Deleted.S>
+                }`)
+
+        return esTopic
+    }
+
+    private addPipe(id: string, esTable: TableV2, esTopic: Topic, esLogs: LogGroup, role: Role, pattern: string, inputTemplate: string) {
+        new CfnPipe(this, id, {
+            roleArn: role.roleArn,
             source: esTable.tableStreamArn!,
             sourceParameters: {
                 dynamoDbStreamParameters: {
                     startingPosition: 'LATEST'
                 },
                 filterCriteria: {
-                    filters: [{
-                        pattern: `{ 
-                            "dynamodb": { 
-                                "NewImage": { 
-                                    "RecordType": { 
-                                        "S": ["stream"] 
-                                    } 
-                                } 
-                            } 
-                        }`
-                    }]
+                    filters: [{pattern}]
                 },
             },
             target: esTopic.topicArn,
             targetParameters: {
-                inputTemplate: `{
-                    "StreamId": <$.dynamodb.Keys.PK.S>,
-                    "StreamType": <$.dynamodb.NewImage.StreamType.S>,
-                    "StreamRevision": <$.dynamodb.NewImage.StreamRevision.N>
-                }`
+                inputTemplate,
             },
             logConfiguration: {
                 includeExecutionData: ['ALL'],
@@ -162,11 +273,9 @@ export class AwsEventStoreStack extends cdk.Stack {
             },
 
         } as CfnPipeProps)
-
-        return esTopic
     }
 
-    private addSsmParameters(esTable: TableV2, esUrl: FunctionUrl, snsTopic: Topic) {
+    private addSsmParameters(esTable: TableV2, payloadBucket: Bucket, payloadKey: Key, pageKeySecret: Secret, esUrl: FunctionUrl, snsTopic: Topic) {
         const appMode = esConfig.appMode
         const prefix = appMode.charAt(0).toUpperCase() + appMode.slice(1)
 
@@ -175,6 +284,21 @@ export class AwsEventStoreStack extends cdk.Stack {
             stringValue: esTable.tableName,
         });
 
+        new StringParameter(this, `${prefix}EsPayloadBucketName`, {
+            parameterName: `/${appMode}/event-store/PAYLOAD_BUCKET_NAME`,
+            stringValue: payloadBucket.bucketName,
+        });
+
+        new StringParameter(this, `${prefix}EsKmsKeyId`, {
+            parameterName: `/${appMode}/event-store/KMS_KEY_ID`,
+            stringValue: payloadKey.keyArn,
+        });
+
+        new StringParameter(this, `${prefix}EsPageKeySecretName`, {
+            parameterName: `/${appMode}/event-store/PAGE_KEY_SECRET_NAME`,
+            stringValue: pageKeySecret.secretName,
+        });
+
         new StringParameter(this, `${prefix}EsPort`, {
             parameterName: `/${appMode}/event-store/PORT`,
             stringValue: '8080',
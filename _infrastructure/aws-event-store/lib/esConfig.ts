@@ -4,14 +4,22 @@ config({
     path: `${__dirname}/../.env`,
 })
 
+// latestTableIndexStep is the last step of the index migration of a deployed table (see README)
+export const latestTableIndexStep = 3
+
 export const esConfig = {
     awsAccount: process.env.ES_AWS_ACCOUNT,
     awsRegion: process.env.ES_AWS_REGION,
     appMode: process.env.ES_APP_MODE?.toLowerCase() ?? '',
+    tableIndexStep: Number(process.env.ES_TABLE_INDEX_STEP ?? latestTableIndexStep),
 }
 
 if (!['development', 'staging', 'production'].includes(esConfig.appMode)) {
     throw new Error(`Invalid ES_APP_MODE [${esConfig.appMode}]. Should be one of 'development', 'staging', 'production']`)
 }
 
+if (!Number.isInteger(esConfig.tableIndexStep) || esConfig.tableIndexStep < 1 || esConfig.tableIndexStep > latestTableIndexStep) {
+    throw new Error(`Invalid ES_TABLE_INDEX_STEP [${process.env.ES_TABLE_INDEX_STEP}]. Should be from 1 to ${latestTableIndexStep}`)
+}
+
 console.log('Running with config', JSON.stringify(esConfig, null, 2))
@@ -16,21 +16,24 @@ import (
 
 func main() {
 	mode := config.NewFromEnv()
+	storage := config.StorageFromEnv()
 	log := logger.New(config.Development)
 	defer eserror.Ignore(log.Sync)
 
-	err := run(mode, log)
+	err := run(mode, storage, log)
 	if err != nil {
 		log.Errorw("startup", "ERROR", err)
 		os.Exit(1)
 	}
 }
 
-func run(mode config.AppMode, log *zap.SugaredLogger) error {
-	webApp, esConfig, err := internal.BootstrapWebApp(mode, log)
+func run(mode config.AppMode, storage config.Storage, log *zap.SugaredLogger) error {
+	webApp, esConfig, closeStorage, err := internal.BootstrapLocalWebApp(mode, storage, log)
 	if err != nil {
 		return err
 	}
+	defer eserror.Ignore(closeStorage)
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", esConfig.Port),
 		Handler: webApp,
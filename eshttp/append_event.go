@@ -1,7 +1,6 @@
 package eshttp
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
@@ -22,23 +21,36 @@ type appendEventResponse struct {
 // Attempt to append an event with inconsistent revision will cause an error.
 //
 // This is one of the guarantees of an Event Store.
+//
+// Failed requests are retried with the same idempotency key, so the event is appended at most once.
 func (c *Client) AppendEvent(streamType string, streamId uuid.UUID, revision int, event estypes.NewEsEvent) (*estypes.Stream, error) {
-	url := c.formatAppendEventUrl(streamType, streamId, revision)
-
-	body, err := json.Marshal(map[string]any{
+	return c.appendEvents(streamType, streamId, revision, map[string]any{
 		"event": event,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal event: %v", err)
-	}
+}
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(body))
+// AppendEvents persists several events at the tail of the stream in a single transaction.
+//
+// The events get consecutive revisions starting from the given one: revision, revision + 1 and so on.
+// Either all the events are appended, or none of them.
+// At most estypes.MaxEventsPerAppend events can be appended at once.
+//
+// The returned stream has the revision of the last appended event.
+func (c *Client) AppendEvents(streamType string, streamId uuid.UUID, revision int, events []estypes.NewEsEvent) (*estypes.Stream, error) {
+	return c.appendEvents(streamType, streamId, revision, map[string]any{
+		"events": events,
+	})
+}
+
+func (c *Client) appendEvents(streamType string, streamId uuid.UUID, revision int, reqBody map[string]any) (*estypes.Stream, error) {
+	url := c.formatAppendEventUrl(streamType, streamId, revision)
+
+	body, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create PUT request: %v", err)
+		return nil, fmt.Errorf("failed to marshal event: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWrite(http.MethodPut, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed PUT to Event Store: %w", err)
 	}
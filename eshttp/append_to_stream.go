@@ -0,0 +1,66 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+	"net/url"
+)
+
+type appendToStreamResponse struct {
+	Stream        estypes.Stream `json:"stream"`
+	FirstRevision int            `json:"firstRevision"`
+}
+
+// AppendToStream persists events after the latest event of the stream in a single transaction,
+// without the need to know the revision of the stream, e.g. for audit logs:
+//
+//	stream, firstRevision, err := esHttpClient.AppendToStream("audit-log", streamId, estypes.ExpectedRevisionAny, event)
+//
+// expectedRevision is one of the estypes.ExpectedRevision... modes, or an exact revision of the stream, e.g. strconv.Itoa(5).
+// The Event Store resolves the revisions of the events and retries when a concurrent append takes them first,
+// unless the expected revision is exact.
+//
+// It returns the stream with the revision of the last appended event, and the revision of the first one.
+func (c *Client) AppendToStream(streamType string, streamId uuid.UUID, expectedRevision string, events ...estypes.NewEsEvent) (*estypes.Stream, int, error) {
+	var reqBody map[string]any
+	switch len(events) {
+	case 0:
+		return nil, 0, errors.New("no events to append")
+	case 1:
+		reqBody = map[string]any{"event": events[0]}
+	default:
+		reqBody = map[string]any{"events": events}
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal events: %v", err)
+	}
+
+	esUrl := c.baseUrl.JoinPath("streams", streamType, streamId.String(), "events")
+	esUrl.RawQuery = url.Values{"expected-revision": []string{expectedRevision}}.Encode()
+
+	resp, err := c.doWrite(http.MethodPost, esUrl.String(), body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed POST to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, 0, ErrorFromHttpResponse(resp, "failed to append events")
+	}
+
+	var respBody appendToStreamResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse response as stream: %w", err)
+	}
+
+	return &respBody.Stream, respBody.FirstRevision, nil
+}
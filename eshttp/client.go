@@ -4,14 +4,23 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+const defaultMaxRetries = 2
+const defaultRetryDelay = 200 * time.Millisecond
+
 type Client struct {
 	baseUrl    url.URL
 	httpClient http.Client
+	maxRetries int
+	retryDelay time.Duration
+	upcasters  map[upcasterKey]Upcaster
 }
 
-func NewClient(baseUrl string) *Client {
+type ClientOption func(*Client)
+
+func NewClient(baseUrl string, options ...ClientOption) *Client {
 	esUrl, err := url.Parse(baseUrl)
 	if err != nil {
 		panic(fmt.Sprint("failed to parse base url: ", baseUrl))
@@ -19,5 +28,28 @@ func NewClient(baseUrl string) *Client {
 
 	httpClient := http.Client{}
 
-	return &Client{baseUrl: *esUrl, httpClient: httpClient}
+	client := &Client{
+		baseUrl:    *esUrl,
+		httpClient: httpClient,
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+		upcasters:  make(map[upcasterKey]Upcaster),
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	return client
+}
+
+// WithRetries sets how many times a failed request is retried, and the delay before the first retry.
+// The delay grows linearly with every next retry.
+//
+// Writes are retried with the same idempotency key, so each of them is applied at most once.
+func WithRetries(maxRetries int, retryDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = retryDelay
+	}
 }
@@ -1,9 +1,9 @@
 package eshttp
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
 	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
 	"net/http"
@@ -19,19 +19,41 @@ type createStreamResponse struct {
 // Streams of the same type support the same types of events and are subject to the same processing.
 //
 // When subscribing to an Event Store updates, stream type can be used as a criteria in SNS subscription filter.
+//
+// Failed requests are retried with the same idempotency key, so a retry never creates a duplicate stream.
 func (c *Client) CreateStream(streamType string, initialEvent estypes.NewEsEvent) (*estypes.Stream, error) {
 	esUrl := c.formatCreateStreamUrl(streamType)
 
-	body, err := json.Marshal(map[string]any{
+	return c.createStream(http.MethodPost, esUrl, map[string]any{
 		"initialEvent": initialEvent,
 	})
+}
+
+// CreateStreamWithId persists new event-sourced stream with the given id together with initial event.
+//
+// Use it when the id of an entity is known before its first event, e.g. derived from a natural key:
+//
+//	streamId := uuid.NewSHA1(orderNamespace, []byte(orderNumber))
+//	stream, err := esHttpClient.CreateStreamWithId("order", streamId, initialEvent)
+//
+// If a stream with this id already exists, an Error with status code 409 is returned.
+func (c *Client) CreateStreamWithId(streamType string, streamId uuid.UUID, initialEvent estypes.NewEsEvent) (*estypes.Stream, error) {
+	esUrl := c.formatCreateStreamWithIdUrl(streamType, streamId)
+
+	return c.createStream(http.MethodPut, esUrl, map[string]any{
+		"initialEvent": initialEvent,
+	})
+}
+
+func (c *Client) createStream(method string, esUrl string, reqBody map[string]any) (*estypes.Stream, error) {
+	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal initial event: %v", err)
 	}
 
-	resp, err := http.Post(esUrl, "application/json", bytes.NewBuffer(body))
+	resp, err := c.doWrite(method, esUrl, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed POST to Event Store: %w", err)
+		return nil, fmt.Errorf("failed %s to Event Store: %w", method, err)
 	}
 
 	defer eserror.Ignore(resp.Body.Close)
@@ -52,3 +74,7 @@ func (c *Client) CreateStream(streamType string, initialEvent estypes.NewEsEvent
 func (c *Client) formatCreateStreamUrl(streamType string) string {
 	return c.baseUrl.JoinPath("streams", streamType).String()
 }
+
+func (c *Client) formatCreateStreamWithIdUrl(streamType string, streamId uuid.UUID) string {
+	return c.baseUrl.JoinPath("streams", streamType, streamId.String()).String()
+}
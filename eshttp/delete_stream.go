@@ -0,0 +1,47 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+	"net/url"
+)
+
+type deleteStreamResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+// DeleteStream deletes a stream, deletion is either estypes.DeletionSoft or estypes.DeletionHard.
+//
+// A soft-deleted stream keeps its events, but reading or appending to it fails with an Error with status code 410.
+// A hard deletion removes the events as well, leaving only the stream record as a tombstone.
+// Soft deletion of an already deleted stream fails with status code 410, while hard deletion may be repeated.
+func (c *Client) DeleteStream(streamType string, streamId uuid.UUID, deletion string) (*estypes.Stream, error) {
+	esUrl := c.baseUrl.JoinPath("streams", streamType, streamId.String())
+	queryValues := url.Values{
+		"mode": []string{deletion},
+	}
+	esUrl.RawQuery = queryValues.Encode()
+
+	resp, err := c.doWrite(http.MethodDelete, esUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed DELETE to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to delete stream")
+	}
+
+	var respBody deleteStreamResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream: %w", err)
+	}
+
+	return &respBody.Stream, nil
+}
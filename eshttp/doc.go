@@ -2,10 +2,25 @@
 //
 // The client wraps the following Event Store operations:
 //   - create event stream with initial event
+//   - create event stream with given id and initial event
 //   - append event to stream
+//   - append several events to stream atomically
+//   - append events at the end of stream with an expected revision mode
+//   - append events to several streams atomically in a transaction
+//   - claim and release unique keys with stream writes, look up the stream holding a key
 //   - get stream details
 //   - list streams
 //   - get stream events
+//   - get single event by revision
+//   - get events of all streams in global order
+//   - get events of all streams of a type in time order
+//   - save and get stream snapshot
+//   - set and get stream metadata
+//   - shred stream payloads
+//   - delete stream (soft or hard)
+//   - truncate stream events before a revision
+//   - list registered stream types with event payload schemas
+//   - upcast payloads of older schema versions when reading events
 //
 // To get started you need a base URL of the Event Store:
 //
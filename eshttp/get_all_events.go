@@ -0,0 +1,91 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type getAllEventsResponse struct {
+	EventPage estypes.AllEventPage `json:"eventPage"`
+}
+
+// GetAllEvents retrieves events of all streams in the order they were appended, till the end of the global log.
+//
+// Every event has a Position in the global log. A job that exports events can save the position
+// of the last processed event and resume after it:
+//
+//	events := esHttpClient.GetAllEvents(lastPosition)
+//	for event, err := range events {
+//	  // process event
+//	  lastPosition = event.Position
+//	}
+//
+// Events come with their StreamType. Payloads of older schema versions are upcast
+// with the upcasters registered with WithUpcaster for the stream type of each event.
+//
+// The returned value is an iterator, result pagination is handled internally.
+func (c *Client) GetAllEvents(afterPosition int) iter.Seq2[*estypes.Event, error] {
+	eventIter := func(yield func(*estypes.Event, error) bool) {
+		for {
+			eventPage, err := c.requestAllEventPage(afterPosition)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, event := range eventPage.Events {
+				if !yield(&event, nil) {
+					return
+				}
+			}
+
+			if !eventPage.HasMore {
+				return
+			}
+
+			afterPosition = eventPage.LastEvaluatedPosition
+		}
+	}
+
+	return eventIter
+}
+
+func (c *Client) requestAllEventPage(afterPosition int) (*estypes.AllEventPage, error) {
+	esUrl := c.baseUrl.JoinPath("all", "events")
+	esUrl.RawQuery = url.Values{
+		"after-position": []string{strconv.Itoa(afterPosition)},
+	}.Encode()
+
+	resp, err := http.Get(esUrl.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed GET events of global log from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to request events of global log")
+	}
+
+	var respBody getAllEventsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as event page: %w", err)
+	}
+
+	for i := range respBody.EventPage.Events {
+		event := &respBody.EventPage.Events[i]
+		err = c.upcast(event.StreamType, event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &respBody.EventPage, nil
+}
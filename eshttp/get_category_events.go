@@ -0,0 +1,96 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type getCategoryEventsResponse struct {
+	EventPage estypes.CategoryEventPage `json:"eventPage"`
+}
+
+// GetCategoryEvents retrieves events of all streams of the type, created at createdAfter or later,
+// in order of creation time and revision, e.g. to rebuild a projection:
+//
+//	events := esHttpClient.GetCategoryEvents("loan-application", time.Now().Add(-24*time.Hour))
+//	for event, err := range events {
+//	  // process event
+//	}
+//
+// The returned value is an iterator, result pagination is handled internally.
+// To save the progress and resume later, use GetCategoryEventPage.
+func (c *Client) GetCategoryEvents(streamType string, createdAfter time.Time) iter.Seq2[*estypes.Event, error] {
+	eventIter := func(yield func(*estypes.Event, error) bool) {
+		nextPageKey := ""
+		for {
+			eventPage, err := c.GetCategoryEventPage(streamType, createdAfter, nextPageKey)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, event := range eventPage.Events {
+				if !yield(&event, nil) {
+					return
+				}
+			}
+
+			if !eventPage.HasMore || eventPage.NextPageKey == nil {
+				return
+			}
+
+			nextPageKey = *eventPage.NextPageKey
+		}
+	}
+
+	return eventIter
+}
+
+// GetCategoryEventPage retrieves a single page of events of all streams of the type.
+//
+// nextPageKey of the previous page continues the read, an empty one starts at createdAfter.
+// The last page has a next page key as well, so it can be saved to read newer events later.
+// Payloads of older schema versions are upcast with the upcasters registered with WithUpcaster.
+func (c *Client) GetCategoryEventPage(streamType string, createdAfter time.Time, nextPageKey string) (*estypes.CategoryEventPage, error) {
+	esUrl := c.baseUrl.JoinPath("categories", streamType, "events")
+
+	queryValues := url.Values{
+		"created-after": []string{createdAfter.UTC().Format(time.RFC3339Nano)},
+	}
+	if nextPageKey != "" {
+		queryValues.Set(estypes.NextPageKeyParam, nextPageKey)
+	}
+	esUrl.RawQuery = queryValues.Encode()
+
+	resp, err := http.Get(esUrl.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed GET category events from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to request category events")
+	}
+
+	var respBody getCategoryEventsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as event page: %w", err)
+	}
+
+	for i := range respBody.EventPage.Events {
+		err = c.upcast(streamType, &respBody.EventPage.Events[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &respBody.EventPage, nil
+}
@@ -0,0 +1,48 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+	"strconv"
+)
+
+type getEventResponse struct {
+	Event estypes.Event `json:"event"`
+}
+
+// GetEvent retrieves a single event of a stream by its revision, e.g. the latest one after a notification:
+//
+//	event, err := esHttpClient.GetEvent("my-stream-type", notification.StreamId, notification.StreamRevision)
+//
+// When there is no such event, an Error with status code 404 is returned.
+func (c *Client) GetEvent(streamType string, streamId uuid.UUID, revision int) (*estypes.Event, error) {
+	esUrl := c.baseUrl.JoinPath("streams", streamType, streamId.String(), "events", strconv.Itoa(revision)).String()
+
+	resp, err := http.Get(esUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed GET event from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to get event")
+	}
+
+	var respBody getEventResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as event: %w", err)
+	}
+
+	err = c.upcast(streamType, &respBody.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &respBody.Event, nil
+}
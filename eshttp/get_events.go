@@ -10,12 +10,54 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 type getEventsResponse struct {
 	EventPage estypes.EventPage `json:"eventPage"`
 }
 
+// EventsOption narrows down the events read by GetEvents.
+type EventsOption func(*eventsQuery)
+
+type eventsQuery struct {
+	afterRevision int
+	toRevision    int
+	backward      bool
+	limit         int
+	eventTypes    []string
+}
+
+// WithBackward makes GetEvents read from the latest event towards the first one.
+// afterRevision is then exclusive in the reading direction, i.e. events before it are read; zero starts at the latest event.
+func WithBackward() EventsOption {
+	return func(q *eventsQuery) {
+		q.backward = true
+	}
+}
+
+// WithToRevision makes GetEvents stop at the given revision (inclusive) in the reading direction.
+func WithToRevision(revision int) EventsOption {
+	return func(q *eventsQuery) {
+		q.toRevision = revision
+	}
+}
+
+// WithLimit makes GetEvents return at most the given number of events.
+func WithLimit(limit int) EventsOption {
+	return func(q *eventsQuery) {
+		q.limit = limit
+	}
+}
+
+// WithEventTypes makes GetEvents return only the events of the given types.
+// The filter is applied by the Event Store, so other events are not transferred.
+func WithEventTypes(eventTypes ...string) EventsOption {
+	return func(q *eventsQuery) {
+		q.eventTypes = append(q.eventTypes, eventTypes...)
+	}
+}
+
 // GetEvents retrieves the stream events in order till the end.
 //
 // afterRevision parameter allows to retrieve newer events after certain revision.
@@ -38,48 +80,85 @@ type getEventsResponse struct {
 //
 //	event, err, isValid := next()
 //	stop()
-func (c *Client) GetEvents(streamType string, streamId uuid.UUID, afterRevision int) iter.Seq2[*estypes.Event, error] {
-	currentAfterRevision := afterRevision
+//
+// Options allow to read backward and to bound the read, e.g. the last 20 events:
+//
+//	events := esHttpClient.GetEvents("my-stream-type", streamId, 0, eshttp.WithBackward(), eshttp.WithLimit(20))
+//
+// Or to read only the events of certain types:
+//
+//	events := esHttpClient.GetEvents("my-stream-type", streamId, 0, eshttp.WithEventTypes("order-placed", "order-cancelled"))
+//
+// Payloads of older schema versions are upcast with the upcasters registered with WithUpcaster.
+func (c *Client) GetEvents(streamType string, streamId uuid.UUID, afterRevision int, options ...EventsOption) iter.Seq2[*estypes.Event, error] {
+	query := eventsQuery{afterRevision: afterRevision}
+	for _, option := range options {
+		option(&query)
+	}
 
 	eventIter := func(yield func(*estypes.Event, error) bool) {
+		pageQuery := query
 		for {
-			eventPage, err := c.requestEventPage(streamType, streamId, currentAfterRevision)
+			eventPage, err := c.requestEventPage(streamType, streamId, pageQuery)
 			if err != nil {
 				yield(nil, err)
 				return
 			}
 
 			for _, event := range eventPage.Events {
+				err = c.upcast(streamType, &event)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
 				if !yield(&event, nil) {
 					return
 				}
 			}
 
+			if query.limit > 0 {
+				pageQuery.limit -= len(eventPage.Events)
+				if pageQuery.limit <= 0 {
+					return
+				}
+			}
+
 			if !eventPage.HasMore {
 				return
 			}
 
-			currentAfterRevision = eventPage.LastEvaluatedRevision
+			pageQuery.afterRevision = eventPage.LastEvaluatedRevision
 		}
 	}
 
 	return eventIter
 }
 
-func (c *Client) formatGetEventsUrl(streamType string, streamId uuid.UUID, afterRevision int) string {
+func (c *Client) formatGetEventsUrl(streamType string, streamId uuid.UUID, query eventsQuery) string {
 	esUrl := c.baseUrl.JoinPath("streams", streamType, streamId.String(), "events")
 
 	queryValues := url.Values{
-		"after-revision": []string{strconv.Itoa(afterRevision)},
+		"after-revision": []string{strconv.Itoa(query.afterRevision)},
+	}
+	if query.backward {
+		queryValues.Set("direction", "backward")
+	}
+	if query.toRevision > 0 {
+		queryValues.Set("to-revision", strconv.Itoa(query.toRevision))
+	}
+	if query.limit > 0 {
+		queryValues.Set("limit", strconv.Itoa(query.limit))
+	}
+	if len(query.eventTypes) > 0 {
+		queryValues.Set("event-types", strings.Join(query.eventTypes, ","))
 	}
-	query := queryValues.Encode()
-	esUrl.RawQuery = query
+	esUrl.RawQuery = queryValues.Encode()
 
 	return esUrl.String()
 }
 
-func (c *Client) requestEventPage(streamType string, streamId uuid.UUID, afterRevision int) (*estypes.EventPage, error) {
-	esUrl := c.formatGetEventsUrl(streamType, streamId, afterRevision)
+func (c *Client) requestEventPage(streamType string, streamId uuid.UUID, query eventsQuery) (*estypes.EventPage, error) {
+	esUrl := c.formatGetEventsUrl(streamType, streamId, query)
 
 	resp, err := http.Get(esUrl)
 	if err != nil {
@@ -0,0 +1,67 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+)
+
+type getStreamTypesResponse struct {
+	StreamTypes []estypes.StreamTypeDefinition `json:"streamTypes"`
+}
+
+type getStreamTypeResponse struct {
+	StreamType estypes.StreamTypeDefinition `json:"streamType"`
+}
+
+// GetStreamTypes lists the stream types of the Event Store registry with their event types and payload schemas.
+// The list is empty when the Event Store runs without a registry.
+func (c *Client) GetStreamTypes() ([]estypes.StreamTypeDefinition, error) {
+	esUrl := c.baseUrl.JoinPath("stream-types").String()
+
+	resp, err := http.Get(esUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed GET stream types from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to get stream types")
+	}
+
+	var respBody getStreamTypesResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream types: %w", err)
+	}
+
+	return respBody.StreamTypes, nil
+}
+
+// GetStreamType retrieves a single stream type of the registry.
+// When the stream type is not registered, an Error with status code 404 is returned.
+func (c *Client) GetStreamType(streamType string) (*estypes.StreamTypeDefinition, error) {
+	esUrl := c.baseUrl.JoinPath("stream-types", streamType).String()
+
+	resp, err := http.Get(esUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed GET stream type from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to get stream type")
+	}
+
+	var respBody getStreamTypeResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream type: %w", err)
+	}
+
+	return &respBody.StreamType, nil
+}
@@ -52,7 +52,7 @@ func (c *Client) formatGetStreamsUrl(streamType string, updatedAfter time.Time,
 		"updated-after": []string{updatedAfterUtc.Format(time.RFC3339Nano)},
 	}
 	if nextPageKey != nil {
-		queryValues.Add("nextPageKey", *nextPageKey)
+		queryValues.Set(estypes.NextPageKeyParam, *nextPageKey)
 	}
 	query := queryValues.Encode()
 	esUrl.RawQuery = query
@@ -62,7 +62,6 @@ func (c *Client) formatGetStreamsUrl(streamType string, updatedAfter time.Time,
 
 func (c *Client) requestStreamPage(streamType string, updatedAfter time.Time, nextPageKey *string) (*estypes.StreamPage, error) {
 	esUrl := c.formatGetStreamsUrl(streamType, updatedAfter, nextPageKey)
-	println("esUrl: %s", esUrl)
 
 	resp, err := http.Get(esUrl)
 	if err != nil {
@@ -0,0 +1,72 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+	"net/url"
+)
+
+type getReservationResponse struct {
+	Reservation estypes.Reservation `json:"reservation"`
+}
+
+// CreateStreamClaiming persists new event-sourced stream together with initial event,
+// and claims unique keys for it in the same transaction, e.g. one account per email:
+//
+//	stream, err := esHttpClient.CreateStreamClaiming("account", initialEvent, estypes.ReservationKey{Namespace: "account-email", Key: email})
+//
+// When another stream holds any of the keys, nothing is written and an Error with status code 409 is returned,
+// its details list the taken keys.
+func (c *Client) CreateStreamClaiming(streamType string, initialEvent estypes.NewEsEvent, claim ...estypes.ReservationKey) (*estypes.Stream, error) {
+	esUrl := c.formatCreateStreamUrl(streamType)
+
+	return c.createStream(http.MethodPost, esUrl, map[string]any{
+		"initialEvent": initialEvent,
+		"claim":        claim,
+	})
+}
+
+// AppendEventsReserving persists events at the tail of the stream, same as AppendEvents,
+// and claims and releases unique keys of the stream in the same transaction, e.g. when an account changes its email.
+//
+// A key can only be released by the stream that holds it. At most estypes.MaxReservationsPerWrite keys
+// can be claimed and released at once, and each of them takes the place of an event in the transaction.
+func (c *Client) AppendEventsReserving(streamType string, streamId uuid.UUID, revision int, claim []estypes.ReservationKey, release []estypes.ReservationKey, events ...estypes.NewEsEvent) (*estypes.Stream, error) {
+	return c.appendEvents(streamType, streamId, revision, map[string]any{
+		"events":  events,
+		"claim":   claim,
+		"release": release,
+	})
+}
+
+// GetReservation tells which stream holds the unique key.
+// When the key is free, an Error with status code 404 is returned.
+func (c *Client) GetReservation(namespace string, key string) (*estypes.Reservation, error) {
+	esUrl := c.baseUrl.JoinPath("reservations")
+	// keep slashes of the namespace and the key within their path segments
+	esUrl.RawPath = esUrl.EscapedPath() + "/" + url.PathEscape(namespace) + "/" + url.PathEscape(key)
+	esUrl.Path = esUrl.Path + "/" + namespace + "/" + key
+
+	resp, err := http.Get(esUrl.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed GET reservation from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to get reservation")
+	}
+
+	var respBody getReservationResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as reservation: %w", err)
+	}
+
+	return &respBody.Reservation, nil
+}
@@ -0,0 +1,42 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+)
+
+type shredPayloadsResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+// ShredPayloads makes the payloads of all events in a stream unreadable by deleting the stream's encryption key.
+//
+// The events stay in the stream and are returned with Shredded flag and no payload.
+// The snapshot of the stream is deleted as well.
+// When the Event Store has no payload encryption configured, an Error with status code 400 is returned.
+func (c *Client) ShredPayloads(streamType string, streamId uuid.UUID) (*estypes.Stream, error) {
+	esUrl := c.baseUrl.JoinPath("streams", streamType, streamId.String(), "payloads").String()
+
+	resp, err := c.doWrite(http.MethodDelete, esUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed DELETE to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to shred payloads")
+	}
+
+	var respBody shredPayloadsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream: %w", err)
+	}
+
+	return &respBody.Stream, nil
+}
@@ -0,0 +1,113 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"iter"
+	"net/http"
+)
+
+type snapshotResponse struct {
+	Snapshot estypes.Snapshot `json:"snapshot"`
+}
+
+// SaveSnapshot stores the state of a stream derived from its events up to the snapshot revision.
+//
+// Only the latest snapshot of a stream is kept.
+// Saving a snapshot of an earlier revision than the stored one fails with status code 409.
+func (c *Client) SaveSnapshot(streamType string, streamId uuid.UUID, snapshot estypes.NewEsSnapshot) (*estypes.Snapshot, error) {
+	esUrl := c.formatSnapshotUrl(streamType, streamId)
+
+	body, err := json.Marshal(map[string]any{
+		"snapshot": snapshot,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %v", err)
+	}
+
+	resp, err := c.doWrite(http.MethodPut, esUrl, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed PUT to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, ErrorFromHttpResponse(resp, "failed to save snapshot")
+	}
+
+	var respBody snapshotResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as snapshot: %w", err)
+	}
+
+	return &respBody.Snapshot, nil
+}
+
+// GetSnapshot retrieves the latest snapshot of a stream.
+//
+// When the stream has no snapshot, an Error with status code 404 is returned.
+func (c *Client) GetSnapshot(streamType string, streamId uuid.UUID) (*estypes.Snapshot, error) {
+	esUrl := c.formatSnapshotUrl(streamType, streamId)
+
+	resp, err := http.Get(esUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed GET snapshot from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to get snapshot")
+	}
+
+	var respBody snapshotResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as snapshot: %w", err)
+	}
+
+	return &respBody.Snapshot, nil
+}
+
+// GetSnapshotAndEvents retrieves the latest snapshot of a stream and the events after it.
+//
+// Only a snapshot of the given schema version is used. When there is no such snapshot,
+// the returned snapshot is nil and the events are read from the start of the stream.
+//
+//	snapshot, events, err := esHttpClient.GetSnapshotAndEvents("my-stream-type", streamId, 2)
+//	// handle err, restore state from snapshot if not nil
+//	for event, err := range events {
+//	  // apply event
+//	}
+func (c *Client) GetSnapshotAndEvents(streamType string, streamId uuid.UUID, schemaVersion int) (*estypes.Snapshot, iter.Seq2[*estypes.Event, error], error) {
+	snapshot, err := c.GetSnapshot(streamType, streamId)
+
+	esErr := &Error{}
+	if errors.As(err, &esErr) && esErr.StatusCode == http.StatusNotFound {
+		snapshot, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if snapshot != nil && snapshot.SchemaVersion != schemaVersion {
+		snapshot = nil
+	}
+
+	afterRevision := 0
+	if snapshot != nil {
+		afterRevision = snapshot.Revision
+	}
+
+	return snapshot, c.GetEvents(streamType, streamId, afterRevision), nil
+}
+
+func (c *Client) formatSnapshotUrl(streamType string, streamId uuid.UUID) string {
+	return c.baseUrl.JoinPath("streams", streamType, streamId.String(), "snapshot").String()
+}
@@ -0,0 +1,76 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+)
+
+type streamMetadataResponse struct {
+	Metadata estypes.StreamMetadata `json:"metadata"`
+}
+
+// SetStreamMetadata replaces the metadata of a stream, e.g. its retention policy, labels and owner.
+//
+// MaxAge and MaxCount apply to reading right away. With ExpireEvents, only the events appended afterwards
+// are deleted from storage once older than MaxAge.
+func (c *Client) SetStreamMetadata(streamType string, streamId uuid.UUID, metadata estypes.StreamMetadata) (*estypes.StreamMetadata, error) {
+	esUrl := c.formatStreamMetadataUrl(streamType, streamId)
+
+	body, err := json.Marshal(map[string]any{
+		"metadata": metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stream metadata: %v", err)
+	}
+
+	resp, err := c.doWrite(http.MethodPut, esUrl, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed PUT to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to set stream metadata")
+	}
+
+	var respBody streamMetadataResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream metadata: %w", err)
+	}
+
+	return &respBody.Metadata, nil
+}
+
+// GetStreamMetadata retrieves the metadata of a stream. A stream without metadata has it empty.
+func (c *Client) GetStreamMetadata(streamType string, streamId uuid.UUID) (*estypes.StreamMetadata, error) {
+	esUrl := c.formatStreamMetadataUrl(streamType, streamId)
+
+	resp, err := http.Get(esUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed GET stream metadata from Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to get stream metadata")
+	}
+
+	var respBody streamMetadataResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream metadata: %w", err)
+	}
+
+	return &respBody.Metadata, nil
+}
+
+func (c *Client) formatStreamMetadataUrl(streamType string, streamId uuid.UUID) string {
+	return c.baseUrl.JoinPath("streams", streamType, streamId.String(), "metadata").String()
+}
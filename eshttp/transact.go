@@ -0,0 +1,58 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+)
+
+type transactionResponse struct {
+	Streams []estypes.Stream `json:"streams"`
+}
+
+// Transact appends events to several streams at their expected revisions in a single transaction, all or nothing:
+//
+//	streams, err := esHttpClient.Transact(
+//		estypes.StreamAppend{StreamType: "account", StreamId: fromId, ExpectedRevision: 3, Events: []estypes.NewEsEvent{withdrawn}},
+//		estypes.StreamAppend{StreamType: "account", StreamId: toId, ExpectedRevision: 7, Events: []estypes.NewEsEvent{deposited}},
+//	)
+//
+// A stream may be listed only once, and at most estypes.MaxTransactionItems streams and events can be written at once.
+// When a stream is not at its expected revision, the Error has status 409 and its details list the conflicting streams.
+// Failed requests are retried with the same idempotency key, so a transaction is applied once.
+//
+// It returns the streams in the order of the appends, with the revisions of their last events.
+func (c *Client) Transact(appends ...estypes.StreamAppend) ([]estypes.Stream, error) {
+	if len(appends) == 0 {
+		return nil, errors.New("no streams to append to")
+	}
+
+	body, err := json.Marshal(map[string]any{"streams": appends})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction: %v", err)
+	}
+
+	esUrl := c.baseUrl.JoinPath("transactions")
+
+	resp, err := c.doWrite(http.MethodPost, esUrl.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed POST to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, ErrorFromHttpResponse(resp, "failed to complete transaction")
+	}
+
+	var respBody transactionResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as streams: %w", err)
+	}
+
+	return respBody.Streams, nil
+}
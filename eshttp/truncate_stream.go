@@ -0,0 +1,49 @@
+package eshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type truncateStreamResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+// TruncateStream deletes the stream events before beforeRevision, e.g. the ones already covered by a snapshot.
+//
+// The stream revision stays the same, and the events from beforeRevision on are kept without gaps.
+// Reading events from before the truncation point starts at beforeRevision.
+// An Error with status code 409 is returned when the stream has no event of beforeRevision
+// or is already truncated at a later revision.
+func (c *Client) TruncateStream(streamType string, streamId uuid.UUID, beforeRevision int) (*estypes.Stream, error) {
+	esUrl := c.baseUrl.JoinPath("streams", streamType, streamId.String(), "events")
+	queryValues := url.Values{
+		"before-revision": []string{strconv.Itoa(beforeRevision)},
+	}
+	esUrl.RawQuery = queryValues.Encode()
+
+	resp, err := c.doWrite(http.MethodDelete, esUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed DELETE to Event Store: %w", err)
+	}
+
+	defer eserror.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrorFromHttpResponse(resp, "failed to truncate stream")
+	}
+
+	var respBody truncateStreamResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response as stream: %w", err)
+	}
+
+	return &respBody.Stream, nil
+}
@@ -0,0 +1,55 @@
+package eshttp
+
+import (
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+)
+
+// Upcaster converts a serialized payload of an event type from one schema version to the next one.
+type Upcaster func(payload []byte) ([]byte, error)
+
+type upcasterKey struct {
+	streamType  string
+	eventType   string
+	fromVersion int
+}
+
+// WithUpcaster registers an upcaster of the event type from the schema version to the next one.
+//
+// Events read with GetEvents, GetEvent, GetCategoryEvents and GetAllEvents go through the chain of upcasters
+// of their type, one version at a time, until there is no upcaster for the version they got to.
+// Upcasters declared in the registry of the Event Store are applied before, on the server.
+//
+//	esHttpClient := eshttp.NewClient(esUrl,
+//	  eshttp.WithUpcaster("order", "order-placed", 1, renameCustomerField),
+//	  eshttp.WithUpcaster("order", "order-placed", 2, addDefaultCurrency),
+//	)
+func WithUpcaster(streamType string, eventType string, fromVersion int, upcaster Upcaster) ClientOption {
+	return func(c *Client) {
+		c.upcasters[upcasterKey{streamType: streamType, eventType: eventType, fromVersion: fromVersion}] = upcaster
+	}
+}
+
+// upcast applies the registered upcasters to the event, shredded events have no payload to upcast.
+func (c *Client) upcast(streamType string, event *estypes.Event) error {
+	if event.Shredded {
+		return nil
+	}
+
+	for {
+		version := event.PayloadVersion()
+		upcaster, ok := c.upcasters[upcasterKey{streamType: streamType, eventType: event.EventType, fromVersion: version}]
+		if !ok {
+			return nil
+		}
+
+		payload, err := upcaster([]byte(event.Payload))
+		if err != nil {
+			return fmt.Errorf("failed to upcast event [%s::%d] of type [%s] from version [%d]: %w",
+				event.StreamId, event.Revision, event.EventType, version, err)
+		}
+
+		event.Payload = string(payload)
+		event.SchemaVersion = version + 1
+	}
+}
@@ -0,0 +1,50 @@
+package eshttp
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"io"
+	"net/http"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// doWrite sends a write request to the Event Store, retrying on network errors and server failures.
+//
+// All attempts carry the same Idempotency-Key header.
+// So when a write has landed but the response was lost, the retry gets the original response.
+func (c *Client) doWrite(method string, url string, body []byte) (*http.Response, error) {
+	idempotencyKey := uuid.NewString()
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s request: %v", method, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+
+		resp, err := c.httpClient.Do(req)
+		if attempt >= c.maxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			eserror.Ignore(resp.Body.Close)
+		}
+
+		time.Sleep(c.retryDelay * time.Duration(attempt+1))
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,12 @@
+package estypes
+
+// AllEventPage is a page of the global log: events of all streams in order of positions.
+//
+// Events that reads of their streams do not return, i.e. of deleted streams, before the truncation point
+// or hidden by the stream metadata, are skipped, while LastEvaluatedPosition still advances past them. When there are no new events,
+// LastEvaluatedPosition stays at the requested position, so the log can be read again from it later.
+type AllEventPage struct {
+	Events                []Event `json:"events"`
+	HasMore               bool    `json:"hasMore"`
+	LastEvaluatedPosition int     `json:"lastEvaluatedPosition"`
+}
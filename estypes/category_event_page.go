@@ -0,0 +1,12 @@
+package estypes
+
+// CategoryEventPage is a page of events of all streams of a stream type, in order of creation time and revision.
+// Events that reads of their streams do not return, same as in AllEventPage, are skipped.
+//
+// NextPageKey points after the last evaluated event. It is present on the last page as well,
+// so that newer events can be read from it later.
+type CategoryEventPage struct {
+	Events      []Event `json:"events"`
+	HasMore     bool    `json:"hasMore"`
+	NextPageKey *string `json:"nextPageKey,omitempty"`
+}
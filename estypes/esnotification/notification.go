@@ -8,6 +8,7 @@ type EsNotification struct {
 	StreamId         uuid.UUID `json:"StreamId"`
 	StreamType       string    `json:"StreamType"`
 	StreamRevision   int       `json:"StreamRevision,string"`
+	Deleted          string    `json:"Deleted,omitempty"`
 	sqsReceiptHandle string
 }
 
@@ -5,20 +5,52 @@ import (
 	"time"
 )
 
+// Event is an event recorded in a stream.
+//
+// Payload keeps its serialized form, use RawPayload or DecodePayload to parse it.
+// Shredded events have no payload anymore: the encryption key of their stream is deleted.
+//
+// Position is the place of the event in the global log of all streams, it grows in the order of appends.
+// Events appended before the global log was introduced have no position.
+//
+// SchemaVersion is the version of the payload shape, it is returned after upcasting when the payload was upcast.
+//
+// StreamType is only set in pages of the global log and of a category.
 type Event struct {
-	StreamId  uuid.UUID `json:"streamId"`
-	Revision  int       `json:"revision"`
-	EventType string    `json:"eventType"`
-	Payload   string    `json:"payload"`
-	CreatedAt time.Time `json:"createdAt"`
+	StreamId      uuid.UUID     `json:"streamId"`
+	StreamType    string        `json:"streamType,omitempty"`
+	Revision      int           `json:"revision"`
+	EventType     string        `json:"eventType"`
+	Payload       string        `json:"payload"`
+	ContentType   string        `json:"contentType,omitempty"`
+	SchemaVersion int           `json:"schemaVersion,omitempty"`
+	Metadata      EventMetadata `json:"metadata"`
+	Shredded      bool          `json:"shredded,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	Position      int           `json:"position,omitempty"`
 }
 
+// FirstSchemaVersion is the schema version of events written without one.
+const FirstSchemaVersion = 1
+
 func NewEvent(streamId uuid.UUID, revision int, newEvent NewEsEvent, now time.Time) Event {
 	return Event{
-		StreamId:  streamId,
-		Revision:  revision,
-		EventType: newEvent.EventType,
-		Payload:   newEvent.Payload,
-		CreatedAt: now,
+		StreamId:      streamId,
+		Revision:      revision,
+		EventType:     newEvent.EventType,
+		Payload:       newEvent.Payload,
+		ContentType:   newEvent.ContentType,
+		SchemaVersion: newEvent.SchemaVersion,
+		Metadata:      newEvent.Metadata,
+		CreatedAt:     now,
 	}
 }
+
+// PayloadVersion returns the schema version of the payload, FirstSchemaVersion when the event has none.
+func (e Event) PayloadVersion() int {
+	if e.SchemaVersion == 0 {
+		return FirstSchemaVersion
+	}
+
+	return e.SchemaVersion
+}
@@ -0,0 +1,14 @@
+package estypes
+
+// EventMetadata describes the context in which an event was recorded.
+//
+// CorrelationId ties together all events of one workflow, even across streams.
+// CausationId refers to the message (command or event) that directly caused this event.
+// Actor is the user or service that made the decision.
+// Headers can carry any other string values.
+type EventMetadata struct {
+	CorrelationId string            `json:"correlationId,omitempty"`
+	CausationId   string            `json:"causationId,omitempty"`
+	Actor         string            `json:"actor,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
@@ -1,7 +1,12 @@
 package estypes
 
+// EventPage is a page of stream events in order of revisions.
+//
+// TruncatedBefore is set when events were requested from before the truncation point of the stream,
+// the page then starts at this revision instead.
 type EventPage struct {
 	Events                []Event `json:"events"`
 	HasMore               bool    `json:"hasMore"`
 	LastEvaluatedRevision int     `json:"lastEvaluatedRevision"`
+	TruncatedBefore       int     `json:"truncatedBefore,omitempty"`
 }
@@ -0,0 +1,13 @@
+package estypes
+
+// Expected revision modes of an append at the end of a stream.
+//
+// With ExpectedRevisionAny events are appended after the latest event, and the stream is created when it does not exist.
+// With ExpectedRevisionStreamExists events are appended after the latest event of an existing stream.
+// With ExpectedRevisionNoStream the stream is created with the events, and an existing stream is a conflict.
+// Otherwise the expected revision is a number, the revision the stream should have before the append.
+const (
+	ExpectedRevisionAny          = "any"
+	ExpectedRevisionStreamExists = "stream-exists"
+	ExpectedRevisionNoStream     = "no-stream"
+)
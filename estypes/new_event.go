@@ -4,7 +4,20 @@ package estypes
 //
 // Event type will tell an event handler how to parse and handle the payload.
 // Payload can be any string, but usually supposed to be a serialized JSON.
+// With ContentType set to ContentTypeJson it is sent over the wire as a JSON value
+// (see NewJsonEvent), otherwise as a string.
+// Metadata is optional and is returned with the event as is.
+// SchemaVersion is the version of the payload shape of the event type, see Event.PayloadVersion.
 type NewEsEvent struct {
-	EventType string `json:"eventType" validate:"required"`
-	Payload   string `json:"payload,omitempty" validate:"required"`
+	EventType     string        `json:"eventType" validate:"required"`
+	Payload       string        `json:"payload,omitempty" validate:"required"`
+	ContentType   string        `json:"contentType,omitempty"`
+	SchemaVersion int           `json:"schemaVersion,omitempty" validate:"gte=0"`
+	Metadata      EventMetadata `json:"metadata"`
 }
+
+// MaxEventsPerAppend is the maximum number of events that can be appended to a stream at once.
+//
+// It is bound by the limit of 100 items in a DynamoDB transaction,
+// which also includes the stream record, the commit record of the global log and the idempotency record.
+const MaxEventsPerAppend = 97
@@ -0,0 +1,152 @@
+package estypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ContentTypeJson marks a payload which is a JSON value.
+//
+// JSON payloads are sent and received as JSON values instead of strings with serialized JSON.
+// Payloads with any other content type (or without one) are sent and received as strings.
+const ContentTypeJson = "application/json"
+
+// NewJsonEvent creates an event with the payload serialized to JSON.
+func NewJsonEvent(eventType string, payload any) (NewEsEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return NewEsEvent{}, fmt.Errorf("failed to serialize payload: %w", err)
+	}
+
+	newEvent := NewEsEvent{
+		EventType:   eventType,
+		Payload:     string(data),
+		ContentType: ContentTypeJson,
+	}
+
+	return newEvent, nil
+}
+
+type newEsEventJson NewEsEvent
+
+func (e NewEsEvent) MarshalJSON() ([]byte, error) {
+	payload, err := marshalPayload(e.Payload, e.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		newEsEventJson
+		Payload json.RawMessage `json:"payload,omitempty"`
+	}{newEsEventJson(e), payload})
+}
+
+func (e *NewEsEvent) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		*newEsEventJson
+		Payload json.RawMessage `json:"payload"`
+	}
+	wire.newEsEventJson = (*newEsEventJson)(e)
+
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return err
+	}
+
+	e.Payload, e.ContentType, err = unmarshalPayload(wire.Payload, e.ContentType)
+
+	return err
+}
+
+type eventJson Event
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	payload, err := marshalPayload(e.Payload, e.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		eventJson
+		Payload json.RawMessage `json:"payload"`
+	}{eventJson(e), payload})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		*eventJson
+		Payload json.RawMessage `json:"payload"`
+	}
+	wire.eventJson = (*eventJson)(e)
+
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return err
+	}
+
+	e.Payload, e.ContentType, err = unmarshalPayload(wire.Payload, e.ContentType)
+
+	return err
+}
+
+// RawPayload returns the payload to be decoded with json.Unmarshal.
+//
+// Payloads without a content type are usually serialized JSON as well, so they are returned as is.
+func (e *Event) RawPayload() json.RawMessage {
+	return json.RawMessage(e.Payload)
+}
+
+// DecodePayload parses the payload as JSON into target.
+func (e *Event) DecodePayload(target any) error {
+	return json.Unmarshal(e.RawPayload(), target)
+}
+
+func marshalPayload(payload string, contentType string) (json.RawMessage, error) {
+	if payload == "" {
+		return nil, nil
+	}
+
+	if contentType == ContentTypeJson {
+		if !json.Valid([]byte(payload)) {
+			return nil, errors.New("payload is not a valid JSON")
+		}
+
+		return json.RawMessage(payload), nil
+	}
+
+	return json.Marshal(payload)
+}
+
+func unmarshalPayload(data json.RawMessage, contentType string) (string, string, error) {
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return "", contentType, nil
+	}
+
+	if data[0] == '"' {
+		var payload string
+		err := json.Unmarshal(data, &payload)
+		if err != nil {
+			return "", "", err
+		}
+
+		if contentType == ContentTypeJson && !json.Valid([]byte(payload)) {
+			return "", "", errors.New("payload is not a valid JSON")
+		}
+
+		return payload, contentType, nil
+	}
+
+	if contentType != "" && contentType != ContentTypeJson {
+		return "", "", fmt.Errorf("payload must be a string for content type %q", contentType)
+	}
+
+	var compacted bytes.Buffer
+	err := json.Compact(&compacted, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return compacted.String(), ContentTypeJson, nil
+}
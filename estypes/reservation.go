@@ -0,0 +1,42 @@
+package estypes
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ReservationKey is a value that should be unique within its namespace, e.g. an email within "account-email".
+type ReservationKey struct {
+	Namespace string `json:"namespace" validate:"required"`
+	Key       string `json:"key" validate:"required"`
+}
+
+// Reservation is a unique key claimed by a stream. It is held until released by a later write to the same stream.
+type Reservation struct {
+	Namespace  string    `json:"namespace"`
+	Key        string    `json:"key"`
+	StreamType string    `json:"streamType"`
+	StreamId   uuid.UUID `json:"streamId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func NewReservation(key ReservationKey, stream Stream, now time.Time) Reservation {
+	return Reservation{
+		Namespace:  key.Namespace,
+		Key:        key.Key,
+		StreamType: stream.StreamType,
+		StreamId:   stream.StreamId,
+		CreatedAt:  now,
+	}
+}
+
+func (r Reservation) ReservationKey() ReservationKey {
+	return ReservationKey{
+		Namespace: r.Namespace,
+		Key:       r.Key,
+	}
+}
+
+// MaxReservationsPerWrite is the maximum number of keys claimed and released by a single write together.
+// Every reservation takes an item of the DynamoDB transaction, so the write has as many events less.
+const MaxReservationsPerWrite = 10
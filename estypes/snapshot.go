@@ -0,0 +1,35 @@
+package estypes
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Snapshot is a state of a stream derived from its events up to and including Revision.
+//
+// State is opaque to the Event Store, usually a serialized JSON.
+// SchemaVersion tells the reader how to parse the state, so snapshots of an outdated schema can be ignored.
+type Snapshot struct {
+	StreamId      uuid.UUID `json:"streamId"`
+	Revision      int       `json:"revision"`
+	SchemaVersion int       `json:"schemaVersion"`
+	State         string    `json:"state"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// NewEsSnapshot is a snapshot to be saved for a stream.
+type NewEsSnapshot struct {
+	Revision      int    `json:"revision" validate:"required,min=1"`
+	SchemaVersion int    `json:"schemaVersion"`
+	State         string `json:"state" validate:"required"`
+}
+
+func NewSnapshot(streamId uuid.UUID, newSnapshot NewEsSnapshot, now time.Time) Snapshot {
+	return Snapshot{
+		StreamId:      streamId,
+		Revision:      newSnapshot.Revision,
+		SchemaVersion: newSnapshot.SchemaVersion,
+		State:         newSnapshot.State,
+		CreatedAt:     now,
+	}
+}
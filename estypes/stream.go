@@ -6,11 +6,26 @@ import (
 	"time"
 )
 
+// Deletion modes of a stream.
+//
+// A soft-deleted stream keeps its events, but they cannot be read or appended to anymore.
+// A hard-deleted stream has its events removed, only the stream record is left as a tombstone.
+const (
+	DeletionSoft = "soft"
+	DeletionHard = "hard"
+)
+
+// Stream is the record of an event stream.
+//
+// TruncatedBefore is the revision of the earliest event kept after truncation, zero when the stream is not truncated.
 type Stream struct {
-	StreamId   uuid.UUID `json:"streamId" dynamodbav:"PK,string"`
-	StreamType string    `json:"streamType" dynamodbav:"streamType"`
-	Revision   int       `json:"revision" dynamodbav:"revision"`
-	UpdatedAt  time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
+	StreamId        uuid.UUID       `json:"streamId" dynamodbav:"PK,string"`
+	StreamType      string          `json:"streamType" dynamodbav:"streamType"`
+	Revision        int             `json:"revision" dynamodbav:"revision"`
+	UpdatedAt       time.Time       `json:"updatedAt" dynamodbav:"updatedAt"`
+	Deleted         string          `json:"deleted,omitempty" dynamodbav:"deleted,omitempty"`
+	TruncatedBefore int             `json:"truncatedBefore,omitempty" dynamodbav:"truncatedBefore,omitempty"`
+	Metadata        *StreamMetadata `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
 }
 
 func NewStream(streamId uuid.UUID, streamType string, now time.Time) Stream {
@@ -37,3 +52,52 @@ func (s *Stream) ShouldHaveRevision(revision int) error {
 
 	return nil
 }
+
+func (s *Stream) ShouldNotBeDeleted() error {
+	if s.Deleted != "" {
+		return fmt.Errorf("stream is deleted; streamId: [%s], deletion: [%s]", s.StreamId, s.Deleted)
+	}
+
+	return nil
+}
+
+// ShouldAllowSoftDeletion checks that the stream is not hard-deleted. A soft deletion may be repeated,
+// e.g. to finish releasing the unique keys of the stream after a failure.
+func (s *Stream) ShouldAllowSoftDeletion() error {
+	if s.Deleted == DeletionHard {
+		return fmt.Errorf("stream is hard-deleted; streamId: [%s]", s.StreamId)
+	}
+
+	return nil
+}
+
+// Hides tells whether the event is not served anymore: it is before the truncation point,
+// even when the truncation has not deleted it yet, or it is hidden by the stream metadata.
+func (s *Stream) Hides(event Event, now time.Time) bool {
+	return event.Revision < s.TruncatedBefore || s.Metadata.Hides(event, s.Revision, now)
+}
+
+// Serves tells whether reads of the stream return the event: the stream is not deleted and does not hide the event.
+// Reads of the global log and of categories skip the events a stream does not serve.
+func (s *Stream) Serves(event Event, now time.Time) bool {
+	return s.Deleted == "" && !s.Hides(event, now)
+}
+
+// ShouldAllowTruncation checks that the stream is live, has an event of the given revision,
+// and is not truncated at a later revision already.
+func (s *Stream) ShouldAllowTruncation(beforeRevision int) error {
+	err := s.ShouldNotBeDeleted()
+	if err != nil {
+		return err
+	}
+
+	if beforeRevision > s.Revision {
+		return fmt.Errorf("stream has no event to truncate before; streamId: [%s], revision: [%d], truncate before: [%d]", s.StreamId, s.Revision, beforeRevision)
+	}
+
+	if beforeRevision < s.TruncatedBefore {
+		return fmt.Errorf("stream is already truncated at a later revision; streamId: [%s], truncated before: [%d], truncate before: [%d]", s.StreamId, s.TruncatedBefore, beforeRevision)
+	}
+
+	return nil
+}
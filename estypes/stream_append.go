@@ -0,0 +1,18 @@
+package estypes
+
+import "github.com/google/uuid"
+
+// StreamAppend is the part of a transaction for one stream: events appended after ExpectedRevision,
+// the revision the stream should have before the transaction.
+type StreamAppend struct {
+	StreamType       string       `json:"streamType" validate:"required"`
+	StreamId         uuid.UUID    `json:"streamId" validate:"required"`
+	ExpectedRevision int          `json:"expectedRevision" validate:"min=1"`
+	Events           []NewEsEvent `json:"events" validate:"required,min=1,dive"`
+}
+
+// MaxTransactionItems is the maximum number of streams and events of a transaction together.
+//
+// It is bound by the limit of 100 items in a DynamoDB transaction,
+// which also includes the commit record of the global log and the idempotency record.
+const MaxTransactionItems = 98
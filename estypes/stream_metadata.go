@@ -0,0 +1,49 @@
+package estypes
+
+import "time"
+
+// StreamMetadata holds per-stream settings.
+//
+// MaxAge (in seconds) and MaxCount limit the events returned when reading the stream:
+// events older than MaxAge and all but the latest MaxCount events are hidden. Zero means no limit.
+// With ExpireEvents, events appended afterwards are also deleted from storage once they are older than MaxAge.
+// Labels and Owner are opaque to the Event Store.
+type StreamMetadata struct {
+	MaxAge       int               `json:"maxAge,omitempty" validate:"min=0"`
+	MaxCount     int               `json:"maxCount,omitempty" validate:"min=0"`
+	ExpireEvents bool              `json:"expireEvents,omitempty" validate:"excluded_without=MaxAge"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Owner        string            `json:"owner,omitempty"`
+}
+
+// EarliestRevision returns the revision of the earliest event not hidden by MaxCount.
+func (m *StreamMetadata) EarliestRevision(streamRevision int) int {
+	if m == nil || m.MaxCount == 0 {
+		return 1
+	}
+
+	return max(1, streamRevision-m.MaxCount+1)
+}
+
+// IsExpired tells whether an event created at the given time is hidden by MaxAge.
+func (m *StreamMetadata) IsExpired(createdAt time.Time, now time.Time) bool {
+	if m == nil || m.MaxAge == 0 {
+		return false
+	}
+
+	return createdAt.Before(now.Add(-time.Duration(m.MaxAge) * time.Second))
+}
+
+// Hides tells whether the event is not to be returned when reading the stream.
+func (m *StreamMetadata) Hides(event Event, streamRevision int, now time.Time) bool {
+	return event.Revision < m.EarliestRevision(streamRevision) || m.IsExpired(event.CreatedAt, now)
+}
+
+// EventTtl is how long new events are kept in storage, zero when they are kept forever.
+func (m *StreamMetadata) EventTtl() time.Duration {
+	if m == nil || !m.ExpireEvents {
+		return 0
+	}
+
+	return time.Duration(m.MaxAge) * time.Second
+}
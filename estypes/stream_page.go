@@ -1,5 +1,9 @@
 package estypes
 
+// NextPageKeyParam is the query parameter to pass NextPageKey of a page to read the next one.
+// Page keys are opaque: they are signed by the Event Store and only accepted for the same listing.
+const NextPageKeyParam = "next-page-key"
+
 type StreamPage struct {
 	Streams     []Stream `json:"streams"`
 	HasMore     bool     `json:"hasMore"`
@@ -0,0 +1,30 @@
+package estypes
+
+import "encoding/json"
+
+// StreamTypeDefinition declares a stream type of the registry and the event types allowed in its streams.
+type StreamTypeDefinition struct {
+	StreamType  string                `json:"streamType"`
+	Description string                `json:"description,omitempty"`
+	EventTypes  []EventTypeDefinition `json:"eventTypes"`
+}
+
+// EventTypeDefinition declares an event type with the JSON Schema of its payload.
+// An event type without a schema accepts any payload.
+//
+// SchemaVersion is the current version of the payload shape (FirstSchemaVersion when omitted),
+// new events get it when they are appended. Upcasters convert payloads of older versions when reading events.
+type EventTypeDefinition struct {
+	EventType     string               `json:"eventType"`
+	Description   string               `json:"description,omitempty"`
+	SchemaVersion int                  `json:"schemaVersion,omitempty"`
+	Schema        json.RawMessage      `json:"schema,omitempty"`
+	Upcasters     []UpcasterDefinition `json:"upcasters,omitempty"`
+}
+
+// UpcasterDefinition converts a payload from FromVersion to the next version with a JSON Patch (RFC 6902).
+// Removing a missing value is ignored, and adding a value creates the missing parents of its path.
+type UpcasterDefinition struct {
+	FromVersion int             `json:"fromVersion"`
+	Patch       json.RawMessage `json:"patch"`
+}
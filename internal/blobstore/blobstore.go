@@ -0,0 +1,13 @@
+package blobstore
+
+import "context"
+
+// BlobStore keeps binary objects by key.
+//
+// Keys are slash-separated paths. Get fails with eserror.NotFoundError for a missing key,
+// while Delete of a missing key succeeds.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
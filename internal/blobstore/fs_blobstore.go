@@ -0,0 +1,106 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FsBlobStore keeps blobs as files in a local directory.
+// It is a stand-in for S3 when running offline.
+type FsBlobStore struct {
+	dir string
+}
+
+var _ BlobStore = (*FsBlobStore)(nil)
+
+func NewFsBlobStore(dir string) (*FsBlobStore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob directory [%s]: %w", dir, err)
+	}
+
+	return &FsBlobStore{dir: dir}, nil
+}
+
+func (s *FsBlobStore) Put(_ context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	// write to a temporary file first, so that readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer eserror.Ignore(func() error { return os.Remove(tmp.Name()) })
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write blob [%s]: %w", key, err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write blob [%s]: %w", key, err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to write blob [%s]: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *FsBlobStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("blob [%s] not found", key)
+		return nil, eserror.NewNotFoundError(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob [%s]: %w", key, err)
+	}
+
+	return data, nil
+}
+
+func (s *FsBlobStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob [%s]: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *FsBlobStore) path(key string) (string, error) {
+	relPath := filepath.FromSlash(key)
+	if !filepath.IsLocal(relPath) {
+		return "", fmt.Errorf("invalid blob key [%s]", key)
+	}
+
+	return filepath.Join(s.dir, relPath), nil
+}
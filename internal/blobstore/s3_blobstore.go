@@ -0,0 +1,76 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"io"
+)
+
+// S3BlobStore keeps blobs as objects in an S3 bucket.
+type S3BlobStore struct {
+	s3Client *s3.Client
+	bucket   string
+}
+
+var _ BlobStore = (*S3BlobStore)(nil)
+
+func NewS3BlobStore(s3Client *s3.Client, bucket string) *S3BlobStore {
+	return &S3BlobStore{
+		s3Client: s3Client,
+		bucket:   bucket,
+	}
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put blob [%s] to S3: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	output, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		err = fmt.Errorf("blob [%s] not found", key)
+		return nil, eserror.NewNotFoundError(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob [%s] from S3: %w", key, err)
+	}
+	defer eserror.Ignore(output.Body.Close)
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob [%s] from S3: %w", key, err)
+	}
+
+	return data, nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete blob [%s] from S3: %w", key, err)
+	}
+
+	return nil
+}
@@ -5,8 +5,15 @@ import (
 	"fmt"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ilia-tolliu/serverless-event-store/internal/blobstore"
 	"github.com/ilia-tolliu/serverless-event-store/internal/config"
+	"github.com/ilia-tolliu/serverless-event-store/internal/keyprovider"
+	"github.com/ilia-tolliu/serverless-event-store/internal/registry"
 	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo/boltrepo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo/memrepo"
 	"github.com/ilia-tolliu/serverless-event-store/internal/webapp"
 	"go.uber.org/zap"
 	"runtime"
@@ -15,7 +22,13 @@ import (
 
 const WebShutdownTimeout = 5 * time.Second
 
-func BootstrapWebApp(mode config.AppMode, log *zap.SugaredLogger) (*webapp.WebApp, *config.EsConfig, error) {
+// BootstrapWebApp starts the Event Store with DynamoDB storage, extraOptions are applied after the configured ones.
+// It fails without a page key secret, since the instances of the Lambda have to accept page keys of each other.
+func BootstrapWebApp(mode config.AppMode, log *zap.SugaredLogger, extraOptions ...func(*webapp.WebApp)) (*webapp.WebApp, *config.EsConfig, error) {
+	return bootstrapDynamoDbWebApp(mode, log, true, extraOptions...)
+}
+
+func bootstrapDynamoDbWebApp(mode config.AppMode, log *zap.SugaredLogger, requirePageKeySecret bool, extraOptions ...func(*webapp.WebApp)) (*webapp.WebApp, *config.EsConfig, error) {
 	startupCtx := context.Background() // todo: maybe use context with deadline?
 
 	log.Infow("startup", "GOMAXPROCS", runtime.GOMAXPROCS(0))
@@ -32,10 +45,162 @@ func BootstrapWebApp(mode config.AppMode, log *zap.SugaredLogger) (*webapp.WebAp
 	}
 	log.Infow("startup", "config", esConfig)
 
+	if requirePageKeySecret && esConfig.PageKeySecret == "" {
+		return nil, nil, fmt.Errorf("no page key secret configured, set parameter [PAGE_KEY_SECRET] or [PAGE_KEY_SECRET_NAME]")
+	}
+
+	var blobs blobstore.BlobStore
+	var keys keyprovider.KeyProvider
+	if esConfig.PayloadBucket != "" {
+		s3Client := s3.NewFromConfig(awsConfig)
+		blobs = blobstore.NewS3BlobStore(s3Client, esConfig.PayloadBucket)
+
+		if esConfig.KmsKeyId != "" {
+			kmsClient := kms.NewFromConfig(awsConfig)
+			keys = keyprovider.NewKmsKeyProvider(kmsClient, esConfig.KmsKeyId, s3Client, esConfig.PayloadBucket)
+		}
+	}
+
+	payloads, err := payloadStorage(esConfig, blobs, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options, err := webAppOptions(esConfig, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	options = append(options, extraOptions...)
+
 	dynamoDb := dynamodb.NewFromConfig(awsConfig)
-	esRepo := repo.NewEsRepo(dynamoDb, esConfig.TableName)
+	esRepo := repo.NewEsRepo(dynamoDb, esConfig.TableName, payloads)
 
-	webApp := webapp.New(esRepo, log)
+	webApp := webapp.New(esRepo, log, options...)
 
 	return webApp, esConfig, nil
 }
+
+// BootstrapLocalWebApp starts the Event Store with the given storage, serving also the expvar metrics.
+// A single local instance may sign page keys with a random secret.
+// The returned function releases the storage and should be called on shutdown.
+func BootstrapLocalWebApp(mode config.AppMode, storage config.Storage, log *zap.SugaredLogger) (*webapp.WebApp, *config.EsConfig, func() error, error) {
+	log.Infow("startup", "storage", storage.String())
+
+	noopClose := func() error { return nil }
+
+	if storage == config.DynamoDbStorage {
+		webApp, esConfig, err := bootstrapDynamoDbWebApp(mode, log, false, webapp.WithDebugVars())
+		return webApp, esConfig, noopClose, err
+	}
+
+	log.Infow("startup", "GOMAXPROCS", runtime.GOMAXPROCS(0))
+	log.Infow("startup", "mode", mode.String())
+
+	esConfig, err := config.EsConfigFromEnv()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config from environment, %w", err)
+	}
+	log.Infow("startup", "config", esConfig)
+
+	options, err := webAppOptions(esConfig, log)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	options = append(options, webapp.WithDebugVars())
+
+	var esRepo repo.EsStore
+	closeStorage := noopClose
+
+	switch storage {
+	case config.MemoryStorage:
+		esRepo = memrepo.NewMemRepo()
+	case config.FileStorage:
+		var blobs blobstore.BlobStore
+		if esConfig.BlobDir != "" {
+			fsBlobStore, err := blobstore.NewFsBlobStore(esConfig.BlobDir)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to open blob storage, %w", err)
+			}
+			blobs = fsBlobStore
+		}
+
+		var keys keyprovider.KeyProvider
+		if esConfig.KeyDir != "" {
+			fileKeyProvider, err := keyprovider.NewFileKeyProvider(esConfig.KeyDir)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to open key storage, %w", err)
+			}
+			keys = fileKeyProvider
+		}
+
+		payloads, err := payloadStorage(esConfig, blobs, keys)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		boltRepo, err := boltrepo.NewBoltRepo(esConfig.DbPath, payloads)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to open file storage, %w", err)
+		}
+		esRepo = boltRepo
+		closeStorage = boltRepo.Close
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported storage [%s]", storage)
+	}
+
+	webApp := webapp.New(esRepo, log, options...)
+
+	return webApp, esConfig, closeStorage, nil
+}
+
+// webAppOptions applies the page key secret and the stream type registry of the configuration.
+func webAppOptions(esConfig *config.EsConfig, log *zap.SugaredLogger) ([]func(*webapp.WebApp), error) {
+	options := pageKeyOptions(esConfig, log)
+
+	if esConfig.RegistryPath != "" {
+		streamTypes, err := registry.Load(esConfig.RegistryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stream type registry, %w", err)
+		}
+		log.Infow("startup", "registeredStreamTypes", len(streamTypes.StreamTypes()))
+
+		options = append(options, webapp.WithRegistry(streamTypes))
+	}
+
+	return options, nil
+}
+
+// pageKeyOptions signs next page keys with the configured secret.
+// Without it, page keys are only accepted by the instance that issued them.
+func pageKeyOptions(esConfig *config.EsConfig, log *zap.SugaredLogger) []func(*webapp.WebApp) {
+	if esConfig.PageKeySecret == "" {
+		log.Warnw("startup", "pageKeys", "no page key secret configured, page keys are signed with a random secret")
+		return nil
+	}
+
+	return []func(*webapp.WebApp){webapp.WithPageKeySecret([]byte(esConfig.PageKeySecret))}
+}
+
+// payloadStorage configures offloading of large payloads to blobs and payload encryption (when given),
+// and payload compression.
+func payloadStorage(esConfig *config.EsConfig, blobs blobstore.BlobStore, keys keyprovider.KeyProvider) (repo.PayloadStorage, error) {
+	var payloads repo.PayloadStorage
+
+	if keys != nil {
+		payloads.Encryption = repo.NewPayloadEncryption(keys)
+	}
+
+	if blobs != nil {
+		payloads.Offload = repo.NewPayloadOffload(blobs, esConfig.OffloadThreshold)
+	}
+
+	if esConfig.PayloadCodec != "" {
+		compression, err := repo.NewPayloadCompression(esConfig.PayloadCodec, esConfig.CompressionThreshold)
+		if err != nil {
+			return repo.PayloadStorage{}, fmt.Errorf("failed to configure payload compression, %w", err)
+		}
+		payloads.Compression = compression
+	}
+
+	return payloads, nil
+}
@@ -7,12 +7,49 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"go.uber.org/zap"
+	"os"
+	"strconv"
 	"strings"
 )
 
+const portKey = "EVENT_STORE_PORT"
+const defaultPort = "8080"
+const dbPathKey = "EVENT_STORE_DB_PATH"
+const defaultDbPath = "event-store.db"
+const blobDirKey = "EVENT_STORE_BLOB_DIR"
+const offloadThresholdKey = "EVENT_STORE_PAYLOAD_OFFLOAD_THRESHOLD"
+const payloadCodecKey = "EVENT_STORE_PAYLOAD_CODEC"
+const compressionThresholdKey = "EVENT_STORE_PAYLOAD_COMPRESSION_THRESHOLD"
+const keyDirKey = "EVENT_STORE_KEY_DIR"
+const pageKeySecretKey = "EVENT_STORE_PAGE_KEY_SECRET"
+const registryPathKey = "EVENT_STORE_REGISTRY_PATH"
+
+// secretsManagerReferencePrefix reads a Secrets Manager secret as an SSM parameter.
+const secretsManagerReferencePrefix = "/aws/reference/secretsmanager/"
+
+// EsConfig is the configuration of the Event Store.
+//
+// Payloads above OffloadThreshold bytes are kept in PayloadBucket (S3) or in BlobDir (file storage),
+// when either is set. Payloads above CompressionThreshold bytes are compressed with PayloadCodec, when it is set.
+// Zero thresholds mean the default ones.
+// Payloads are encrypted with per-stream data keys, when KmsKeyId (with PayloadBucket for the wrapped keys)
+// or KeyDir (file storage) is set.
+// PageKeySecret signs next page keys of listings, it is kept out of the logs. In AWS it is either
+// the PAGE_KEY_SECRET parameter, or the Secrets Manager secret named by PAGE_KEY_SECRET_NAME (created by the stack).
+// RegistryPath is the file of the stream type registry, when it is set only registered types are accepted.
 type EsConfig struct {
-	Port      string
-	TableName string
+	Port                 string
+	TableName            string
+	DbPath               string
+	PayloadBucket        string
+	BlobDir              string
+	OffloadThreshold     int
+	PayloadCodec         string
+	CompressionThreshold int
+	KmsKeyId             string
+	KeyDir               string
+	PageKeySecret        string `json:"-"`
+	RegistryPath         string
 }
 
 type EsTestConfig struct {
@@ -39,12 +76,90 @@ func EsConfigFromAws(ctx context.Context, mode AppMode, awsConfig aws.Config, lo
 		return nil, err
 	}
 
+	payloadBucket := extractOptionalParameter(params, "PAYLOAD_BUCKET_NAME")
+
+	offloadThreshold, err := parseThreshold(extractOptionalParameter(params, "PAYLOAD_OFFLOAD_THRESHOLD"))
+	if err != nil {
+		return nil, err
+	}
+
+	payloadCodec := extractOptionalParameter(params, "PAYLOAD_CODEC")
+
+	compressionThreshold, err := parseThreshold(extractOptionalParameter(params, "PAYLOAD_COMPRESSION_THRESHOLD"))
+	if err != nil {
+		return nil, err
+	}
+
+	pageKeySecret, err := loadPageKeySecret(ctx, awsConfig, params)
+	if err != nil {
+		return nil, err
+	}
+
+	kmsKeyId := extractOptionalParameter(params, "KMS_KEY_ID")
+	if kmsKeyId != "" && payloadBucket == "" {
+		return nil, fmt.Errorf("parameter [KMS_KEY_ID] requires parameter [PAYLOAD_BUCKET_NAME] for the wrapped keys")
+	}
+
+	return &EsConfig{
+		Port:                 port,
+		TableName:            tableName,
+		PayloadBucket:        payloadBucket,
+		OffloadThreshold:     offloadThreshold,
+		PayloadCodec:         payloadCodec,
+		CompressionThreshold: compressionThreshold,
+		KmsKeyId:             kmsKeyId,
+		PageKeySecret:        pageKeySecret,
+		RegistryPath:         extractOptionalParameter(params, "REGISTRY_PATH"),
+	}, nil
+}
+
+func EsConfigFromEnv() (*EsConfig, error) {
+	port := os.Getenv(portKey)
+	if port == "" {
+		port = defaultPort
+	}
+
+	dbPath := os.Getenv(dbPathKey)
+	if dbPath == "" {
+		dbPath = defaultDbPath
+	}
+
+	offloadThreshold, err := parseThreshold(os.Getenv(offloadThresholdKey))
+	if err != nil {
+		return nil, err
+	}
+
+	compressionThreshold, err := parseThreshold(os.Getenv(compressionThresholdKey))
+	if err != nil {
+		return nil, err
+	}
+
 	return &EsConfig{
-		Port:      port,
-		TableName: tableName,
+		Port:                 port,
+		DbPath:               dbPath,
+		BlobDir:              os.Getenv(blobDirKey),
+		OffloadThreshold:     offloadThreshold,
+		PayloadCodec:         os.Getenv(payloadCodecKey),
+		CompressionThreshold: compressionThreshold,
+		KeyDir:               os.Getenv(keyDirKey),
+		PageKeySecret:        os.Getenv(pageKeySecretKey),
+		RegistryPath:         os.Getenv(registryPathKey),
 	}, nil
 }
 
+func parseThreshold(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	threshold, err := strconv.Atoi(value)
+	if err != nil || threshold < 0 {
+		return 0, fmt.Errorf("invalid payload size threshold [%s]", value)
+	}
+
+	return threshold, nil
+}
+
 func EsTestConfigFromAws(ctx context.Context, mode AppMode, awsConfig aws.Config) (*EsTestConfig, error) {
 	path := configPrefix(mode)
 
@@ -86,13 +201,47 @@ func configPrefix(mode AppMode) string {
 
 func loadSsmParams(ctx context.Context, awsConfig aws.Config, path string) ([]types.Parameter, error) {
 	ssmClient := ssm.NewFromConfig(awsConfig)
-	ssmOutput, err := ssmClient.GetParametersByPath(ctx, &ssm.GetParametersByPathInput{Path: &path})
+	// secrets, e.g. PAGE_KEY_SECRET, are kept as SecureString parameters
+	paginator := ssm.NewGetParametersByPathPaginator(ssmClient, &ssm.GetParametersByPathInput{
+		Path:           &path,
+		WithDecryption: aws.Bool(true),
+	})
+
+	var params []types.Parameter
+	for paginator.HasMorePages() {
+		ssmOutput, err := paginator.NextPage(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to load SSM parameters by path [%s]: %w", path, err)
+			return []types.Parameter{}, err
+		}
+		params = append(params, ssmOutput.Parameters...)
+	}
+
+	return params, nil
+}
+
+// loadPageKeySecret reads the PAGE_KEY_SECRET parameter, or else the secret named by PAGE_KEY_SECRET_NAME
+// through the Secrets Manager reference of SSM. It is empty when neither is set.
+func loadPageKeySecret(ctx context.Context, awsConfig aws.Config, params []types.Parameter) (string, error) {
+	if secret := extractOptionalParameter(params, "PAGE_KEY_SECRET"); secret != "" {
+		return secret, nil
+	}
+
+	secretName := extractOptionalParameter(params, "PAGE_KEY_SECRET_NAME")
+	if secretName == "" {
+		return "", nil
+	}
+
+	ssmClient := ssm.NewFromConfig(awsConfig)
+	output, err := ssmClient.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(secretsManagerReferencePrefix + secretName),
+		WithDecryption: aws.Bool(true),
+	})
 	if err != nil {
-		err = fmt.Errorf("failed to load SSM parameters by path [%s]: %w", path, err)
-		return []types.Parameter{}, err
+		return "", fmt.Errorf("failed to load page key secret [%s]: %w", secretName, err)
 	}
 
-	return ssmOutput.Parameters, nil
+	return aws.ToString(output.Parameter.Value), nil
 }
 
 func extractParameter(params []types.Parameter, key string) (string, error) {
@@ -106,3 +255,12 @@ func extractParameter(params []types.Parameter, key string) (string, error) {
 
 	return "", fmt.Errorf("parameter [%s] not found", key)
 }
+
+func extractOptionalParameter(params []types.Parameter, key string) string {
+	value, err := extractParameter(params, key)
+	if err != nil {
+		return ""
+	}
+
+	return value
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+type Storage string
+
+const storageKey = "EVENT_STORE_STORAGE"
+
+const (
+	DynamoDbStorage = Storage("dynamodb")
+	MemoryStorage   = Storage("memory")
+	FileStorage     = Storage("file")
+)
+
+func StorageFromEnv() Storage {
+	storageStr := os.Getenv(storageKey)
+	var storage Storage
+
+	switch strings.ToLower(storageStr) {
+	case "", string(DynamoDbStorage):
+		storage = DynamoDbStorage
+	case string(MemoryStorage):
+		storage = MemoryStorage
+	case string(FileStorage):
+		storage = FileStorage
+	default:
+		log.Fatalf("unknown storage: [%s]", storageStr)
+	}
+
+	return storage
+}
+
+func (storage Storage) String() string {
+	return string(storage)
+}
@@ -1,9 +1,13 @@
 package eserror
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/google/uuid"
+)
 
 type DataConflictError struct {
-	Err error
+	Err     error
+	Details *ConflictDetails
 }
 
 func NewDataConflictError(err error) *DataConflictError {
@@ -12,6 +16,23 @@ func NewDataConflictError(err error) *DataConflictError {
 	}
 }
 
+// NewStreamConflictError reports the streams of a multi-stream write that are not at their expected revisions.
+func NewStreamConflictError(err error, conflicts ...StreamConflict) *DataConflictError {
+	return &DataConflictError{
+		Err:     err,
+		Details: &ConflictDetails{Streams: conflicts},
+	}
+}
+
+// NewReservationConflictError reports the unique keys of a write that are claimed by another stream,
+// or that cannot be released since the stream does not hold them.
+func NewReservationConflictError(err error, conflicts ...ReservationConflict) *DataConflictError {
+	return &DataConflictError{
+		Err:     err,
+		Details: &ConflictDetails{Reservations: conflicts},
+	}
+}
+
 func (e *DataConflictError) Error() string {
 	return fmt.Errorf("data conflict: %w", e.Err).Error()
 }
@@ -19,3 +40,18 @@ func (e *DataConflictError) Error() string {
 func (e *DataConflictError) Unwrap() error {
 	return e.Err
 }
+
+type ConflictDetails struct {
+	Streams      []StreamConflict      `json:"streams,omitempty"`
+	Reservations []ReservationConflict `json:"reservations,omitempty"`
+}
+
+type StreamConflict struct {
+	StreamType string    `json:"streamType"`
+	StreamId   uuid.UUID `json:"streamId"`
+}
+
+type ReservationConflict struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+}
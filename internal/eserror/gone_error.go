@@ -0,0 +1,19 @@
+package eserror
+
+import "fmt"
+
+type GoneError struct {
+	Err error
+}
+
+func NewGoneError(err error) *GoneError {
+	return &GoneError{Err: err}
+}
+
+func (e *GoneError) Error() string {
+	return fmt.Errorf("gone: %w", e.Err).Error()
+}
+
+func (e *GoneError) Unwrap() error {
+	return e.Err
+}
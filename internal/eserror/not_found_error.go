@@ -3,13 +3,22 @@ package eserror
 import "fmt"
 
 type NotFoundError struct {
-	Err error
+	Err     error
+	Details *NotFoundDetails
 }
 
 func NewNotFoundError(err error) *NotFoundError {
 	return &NotFoundError{Err: err}
 }
 
+// NewStreamNotFoundError reports the streams of a multi-stream write that do not exist or have another type.
+func NewStreamNotFoundError(err error, streams ...StreamConflict) *NotFoundError {
+	return &NotFoundError{
+		Err:     err,
+		Details: &NotFoundDetails{Streams: streams},
+	}
+}
+
 func (e *NotFoundError) Error() string {
 	return fmt.Errorf("not found: %w", e.Err).Error()
 }
@@ -17,3 +26,7 @@ func (e *NotFoundError) Error() string {
 func (e *NotFoundError) Unwrap() error {
 	return e.Err
 }
+
+type NotFoundDetails struct {
+	Streams []StreamConflict `json:"streams"`
+}
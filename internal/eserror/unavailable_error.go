@@ -0,0 +1,20 @@
+package eserror
+
+import "fmt"
+
+// UnavailableError is a write that could not complete because of contention, it may succeed when retried later.
+type UnavailableError struct {
+	Err error
+}
+
+func NewUnavailableError(err error) *UnavailableError {
+	return &UnavailableError{Err: err}
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Errorf("unavailable: %w", e.Err).Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
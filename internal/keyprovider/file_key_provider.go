@@ -0,0 +1,112 @@
+package keyprovider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileKeyProvider keeps plain data keys as files in a local directory.
+// It is meant for development only.
+type FileKeyProvider struct {
+	dir string
+}
+
+var _ KeyProvider = (*FileKeyProvider)(nil)
+
+type keyFile struct {
+	KeyId string `json:"keyId"`
+	Key   []byte `json:"key"`
+}
+
+func NewFileKeyProvider(dir string) (*FileKeyProvider, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key directory [%s]: %w", dir, err)
+	}
+
+	return &FileKeyProvider{dir: dir}, nil
+}
+
+func (p *FileKeyProvider) CreateKey(ctx context.Context, streamId uuid.UUID) (DataKey, error) {
+	dataKey := DataKey{
+		KeyId: uuid.NewString(),
+		Key:   make([]byte, KeySize),
+	}
+	_, err := rand.Read(dataKey.Key)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	content, err := json.Marshal(keyFile(dataKey))
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to marshal data key: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(p.dir, ".key-*")
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to create data key file [%s]: %w", streamId, err)
+	}
+	defer eserror.Ignore(func() error { return os.Remove(tmp.Name()) })
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+		return DataKey{}, fmt.Errorf("failed to write data key [%s]: %w", streamId, err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to write data key [%s]: %w", streamId, err)
+	}
+
+	// linking fails when the key exists: the first writer wins, the others read its key
+	err = os.Link(tmp.Name(), p.path(streamId))
+	if errors.Is(err, fs.ErrExist) {
+		return p.GetKey(ctx, streamId)
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to write data key [%s]: %w", streamId, err)
+	}
+
+	return dataKey, nil
+}
+
+func (p *FileKeyProvider) GetKey(_ context.Context, streamId uuid.UUID) (DataKey, error) {
+	content, err := os.ReadFile(p.path(streamId))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("data key of stream [%s] not found", streamId)
+		return DataKey{}, eserror.NewNotFoundError(err)
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to read data key [%s]: %w", streamId, err)
+	}
+
+	var stored keyFile
+	err = json.Unmarshal(content, &stored)
+	if err != nil || len(stored.Key) != KeySize {
+		return DataKey{}, fmt.Errorf("data key [%s] is malformed", streamId)
+	}
+
+	return DataKey(stored), nil
+}
+
+func (p *FileKeyProvider) DeleteKey(_ context.Context, streamId uuid.UUID) error {
+	err := os.Remove(p.path(streamId))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete data key [%s]: %w", streamId, err)
+	}
+
+	return nil
+}
+
+func (p *FileKeyProvider) path(streamId uuid.UUID) string {
+	return filepath.Join(p.dir, streamId.String()+".key")
+}
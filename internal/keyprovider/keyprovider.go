@@ -0,0 +1,30 @@
+package keyprovider
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// KeySize is the size of a data key, suitable for AES-256.
+const KeySize = 32
+
+// DataKey is a key of a stream.
+//
+// A stream may get a new key after the previous one is deleted,
+// KeyId tells which of them was used to encrypt the data.
+type DataKey struct {
+	KeyId string
+	Key   []byte
+}
+
+// KeyProvider manages per-stream data keys.
+//
+// Deleting the key of a stream makes everything encrypted with it unreadable (crypto-shredding).
+type KeyProvider interface {
+	// CreateKey returns the data key of the stream, creating it when the stream has none yet.
+	// Concurrent calls for the same stream return the same key.
+	CreateKey(ctx context.Context, streamId uuid.UUID) (DataKey, error)
+	// GetKey fails with eserror.NotFoundError when the stream has no key, e.g. after it is deleted.
+	GetKey(ctx context.Context, streamId uuid.UUID) (DataKey, error)
+	DeleteKey(ctx context.Context, streamId uuid.UUID) error
+}
@@ -0,0 +1,147 @@
+package keyprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+const wrappedKeyPrefix = "keys/"
+
+// KmsApi is the part of the KMS client used for envelope encryption of data keys.
+type KmsApi interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KmsKeyProvider generates data keys with a KMS key and keeps them wrapped (encrypted) in an S3 bucket.
+//
+// Plain data keys never leave memory. The bucket must not be versioned,
+// otherwise deleted keys would survive as previous versions.
+type KmsKeyProvider struct {
+	kmsApi   KmsApi
+	kmsKeyId string
+	s3Client *s3.Client
+	bucket   string
+}
+
+var _ KeyProvider = (*KmsKeyProvider)(nil)
+
+func NewKmsKeyProvider(kmsApi KmsApi, kmsKeyId string, s3Client *s3.Client, bucket string) *KmsKeyProvider {
+	return &KmsKeyProvider{
+		kmsApi:   kmsApi,
+		kmsKeyId: kmsKeyId,
+		s3Client: s3Client,
+		bucket:   bucket,
+	}
+}
+
+type wrappedKey struct {
+	KeyId      string `json:"keyId"`
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+func (p *KmsKeyProvider) CreateKey(ctx context.Context, streamId uuid.UUID) (DataKey, error) {
+	dataKey, err := p.GetKey(ctx, streamId)
+	if err == nil {
+		return dataKey, nil
+	}
+	if !errors.As(err, new(*eserror.NotFoundError)) {
+		return DataKey{}, err
+	}
+
+	output, err := p.kmsApi.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(p.kmsKeyId),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: encryptionContext(streamId),
+	})
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to generate data key [%s]: %w", streamId, err)
+	}
+
+	stored := wrappedKey{
+		KeyId:      uuid.NewString(),
+		WrappedKey: output.CiphertextBlob,
+	}
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to marshal data key: %w", err)
+	}
+
+	// the condition makes the first writer win, the others read its key
+	_, err = p.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucket),
+		Key:         aws.String(wrappedKeyPrefix + streamId.String()),
+		Body:        bytes.NewReader(content),
+		IfNoneMatch: aws.String("*"),
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "PreconditionFailed" || apiErr.ErrorCode() == "ConditionalRequestConflict") {
+		return p.GetKey(ctx, streamId)
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to store data key [%s]: %w", streamId, err)
+	}
+
+	return DataKey{KeyId: stored.KeyId, Key: output.Plaintext}, nil
+}
+
+func (p *KmsKeyProvider) GetKey(ctx context.Context, streamId uuid.UUID) (DataKey, error) {
+	output, err := p.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(wrappedKeyPrefix + streamId.String()),
+	})
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		err = fmt.Errorf("data key of stream [%s] not found", streamId)
+		return DataKey{}, eserror.NewNotFoundError(err)
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to load data key [%s]: %w", streamId, err)
+	}
+	defer eserror.Ignore(output.Body.Close)
+
+	var stored wrappedKey
+	err = json.NewDecoder(output.Body).Decode(&stored)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to load data key [%s]: %w", streamId, err)
+	}
+
+	decrypted, err := p.kmsApi.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(p.kmsKeyId),
+		CiphertextBlob:    stored.WrappedKey,
+		EncryptionContext: encryptionContext(streamId),
+	})
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to decrypt data key [%s]: %w", streamId, err)
+	}
+
+	return DataKey{KeyId: stored.KeyId, Key: decrypted.Plaintext}, nil
+}
+
+func (p *KmsKeyProvider) DeleteKey(ctx context.Context, streamId uuid.UUID) error {
+	_, err := p.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(wrappedKeyPrefix + streamId.String()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete data key [%s]: %w", streamId, err)
+	}
+
+	return nil
+}
+
+// encryptionContext binds a wrapped key to its stream, so it cannot be used for another one.
+func encryptionContext(streamId uuid.UUID) map[string]string {
+	return map[string]string{"streamId": streamId.String()}
+}
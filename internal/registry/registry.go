@@ -0,0 +1,246 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Registry declares the stream types accepted by the Event Store, the event types of each stream type,
+// and JSON Schemas of the event payloads.
+type Registry struct {
+	definitions []estypes.StreamTypeDefinition
+	streamTypes map[string]map[string]eventTypeEntry
+}
+
+// eventTypeEntry is a compiled event type definition, schema is nil when any payload is accepted.
+type eventTypeEntry struct {
+	schema        *jsonschema.Schema
+	schemaVersion int
+	upcasters     map[int]jsonpatch.Patch
+}
+
+// registryFile is the format of the registry config file.
+type registryFile struct {
+	StreamTypes []estypes.StreamTypeDefinition `json:"streamTypes"`
+}
+
+var printer = message.NewPrinter(language.English)
+
+// Load reads the registry from a JSON file with a list of stream type definitions:
+//
+//	{"streamTypes": [{"streamType": "order", "eventTypes": [{"eventType": "order-placed", "schema": {...}}]}]}
+func Load(path string) (*Registry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry file [%s]: %w", path, err)
+	}
+
+	var file registryFile
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse registry file [%s]: %w", path, err)
+	}
+
+	return New(file.StreamTypes)
+}
+
+// New compiles the payload schemas of the stream type definitions.
+func New(definitions []estypes.StreamTypeDefinition) (*Registry, error) {
+	compiler := jsonschema.NewCompiler()
+	streamTypes := make(map[string]map[string]eventTypeEntry, len(definitions))
+
+	for _, definition := range definitions {
+		if definition.StreamType == "" {
+			return nil, errors.New("stream type without a name in registry")
+		}
+		if _, ok := streamTypes[definition.StreamType]; ok {
+			return nil, fmt.Errorf("stream type [%s] is declared twice in registry", definition.StreamType)
+		}
+
+		eventTypes := make(map[string]eventTypeEntry, len(definition.EventTypes))
+		for _, eventType := range definition.EventTypes {
+			if eventType.EventType == "" {
+				return nil, fmt.Errorf("event type without a name in stream type [%s]", definition.StreamType)
+			}
+			if _, ok := eventTypes[eventType.EventType]; ok {
+				return nil, fmt.Errorf("event type [%s] is declared twice in stream type [%s]", eventType.EventType, definition.StreamType)
+			}
+
+			schema, err := compileSchema(compiler, definition.StreamType, eventType)
+			if err != nil {
+				return nil, err
+			}
+
+			schemaVersion := eventType.SchemaVersion
+			if schemaVersion == 0 {
+				schemaVersion = estypes.FirstSchemaVersion
+			}
+
+			upcasters, err := decodeUpcasters(definition.StreamType, eventType, schemaVersion)
+			if err != nil {
+				return nil, err
+			}
+
+			eventTypes[eventType.EventType] = eventTypeEntry{
+				schema:        schema,
+				schemaVersion: schemaVersion,
+				upcasters:     upcasters,
+			}
+		}
+
+		streamTypes[definition.StreamType] = eventTypes
+	}
+
+	registry := &Registry{
+		definitions: definitions,
+		streamTypes: streamTypes,
+	}
+
+	return registry, nil
+}
+
+func compileSchema(compiler *jsonschema.Compiler, streamType string, eventType estypes.EventTypeDefinition) (*jsonschema.Schema, error) {
+	if len(eventType.Schema) == 0 {
+		return nil, nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(eventType.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema of event type [%s::%s]: %w", streamType, eventType.EventType, err)
+	}
+
+	location := fmt.Sprintf("urn:stream-types:%s:%s", url.PathEscape(streamType), url.PathEscape(eventType.EventType))
+	err = compiler.AddResource(location, doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add schema of event type [%s::%s]: %w", streamType, eventType.EventType, err)
+	}
+
+	schema, err := compiler.Compile(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema of event type [%s::%s]: %w", streamType, eventType.EventType, err)
+	}
+
+	return schema, nil
+}
+
+// StreamTypes returns the definitions in the order of the registry file.
+func (r *Registry) StreamTypes() []estypes.StreamTypeDefinition {
+	return r.definitions
+}
+
+func (r *Registry) StreamType(streamType string) (estypes.StreamTypeDefinition, bool) {
+	for _, definition := range r.definitions {
+		if definition.StreamType == streamType {
+			return definition, true
+		}
+	}
+
+	return estypes.StreamTypeDefinition{}, false
+}
+
+// ValidateStreamType fails with eserror.ValidationError when the stream type is not registered.
+func (r *Registry) ValidateStreamType(streamType string) error {
+	if _, ok := r.streamTypes[streamType]; ok {
+		return nil
+	}
+
+	err := fmt.Errorf("stream type [%s] is not registered", streamType)
+	validationErrors := eserror.NewSimpleValidationError("streamType", "not registered")
+
+	return eserror.NewValidationError(err, validationErrors)
+}
+
+// ValidateEvents checks that the event types are registered for the stream type,
+// the schema versions are current (when given) and the payloads match their schemas.
+// Events are keyed with their field path in the request body, e.g. "initialEvent" or "events[1]".
+//
+// Violations are returned as eserror.ValidationError, keyed with the field path
+// and the JSON Pointer of the value inside the payload, e.g. "events[1].payload/items/0/price".
+func (r *Registry) ValidateEvents(streamType string, events map[string]estypes.NewEsEvent) error {
+	err := r.ValidateStreamType(streamType)
+	if err != nil {
+		return err
+	}
+
+	eventTypes := r.streamTypes[streamType]
+	validationErrors := eserror.NewEmptyValidationErrors()
+
+	for field, event := range events {
+		eventType, ok := eventTypes[event.EventType]
+		if !ok {
+			key := field + ".eventType"
+			validationErrors.Messages[key] = append(validationErrors.Messages[key], "not registered")
+			continue
+		}
+		if event.SchemaVersion != 0 && event.SchemaVersion != eventType.schemaVersion {
+			key := field + ".schemaVersion"
+			message := fmt.Sprintf("must be %d", eventType.schemaVersion)
+			validationErrors.Messages[key] = append(validationErrors.Messages[key], message)
+		}
+		if eventType.schema == nil {
+			continue
+		}
+
+		validatePayload(eventType.schema, field+".payload", event.Payload, validationErrors)
+	}
+
+	if len(validationErrors.Messages) == 0 {
+		return nil
+	}
+
+	err = fmt.Errorf("events do not conform to stream type [%s]", streamType)
+
+	return eserror.NewValidationError(err, validationErrors)
+}
+
+// SchemaVersion returns the current schema version of a registered event type.
+func (r *Registry) SchemaVersion(streamType string, eventType string) (int, bool) {
+	entry, ok := r.streamTypes[streamType][eventType]
+
+	return entry.schemaVersion, ok
+}
+
+func validatePayload(schema *jsonschema.Schema, field string, payload string, validationErrors eserror.ValidationErrors) {
+	value, err := jsonschema.UnmarshalJSON(strings.NewReader(payload))
+	if err != nil {
+		validationErrors.Messages[field] = append(validationErrors.Messages[field], "invalid JSON")
+		return
+	}
+
+	err = schema.Validate(value)
+	schemaErr := &jsonschema.ValidationError{}
+	if errors.As(err, &schemaErr) {
+		collectSchemaErrors(schemaErr, field, validationErrors)
+	}
+}
+
+// collectSchemaErrors keeps the leaf errors of the schema validation, which point at the offending values.
+func collectSchemaErrors(schemaErr *jsonschema.ValidationError, field string, validationErrors eserror.ValidationErrors) {
+	if len(schemaErr.Causes) == 0 {
+		key := field
+		for _, token := range schemaErr.InstanceLocation {
+			token = strings.ReplaceAll(token, "~", "~0")
+			key += "/" + strings.ReplaceAll(token, "/", "~1")
+		}
+		message := schemaErr.ErrorKind.LocalizedString(printer)
+		validationErrors.Messages[key] = append(validationErrors.Messages[key], message)
+		return
+	}
+
+	for _, cause := range schemaErr.Causes {
+		collectSchemaErrors(cause, field, validationErrors)
+	}
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+)
+
+var upcastOptions = newUpcastOptions()
+
+func newUpcastOptions() *jsonpatch.ApplyOptions {
+	options := jsonpatch.NewApplyOptions()
+	options.AllowMissingPathOnRemove = true
+	options.EnsurePathExistsOnAdd = true
+	options.EscapeHTML = false
+
+	return options
+}
+
+func decodeUpcasters(streamType string, eventType estypes.EventTypeDefinition, schemaVersion int) (map[int]jsonpatch.Patch, error) {
+	upcasters := make(map[int]jsonpatch.Patch, len(eventType.Upcasters))
+
+	for _, upcaster := range eventType.Upcasters {
+		if upcaster.FromVersion < estypes.FirstSchemaVersion || upcaster.FromVersion >= schemaVersion {
+			return nil, fmt.Errorf("upcaster of event type [%s::%s] from version [%d] is out of versions before [%d]",
+				streamType, eventType.EventType, upcaster.FromVersion, schemaVersion)
+		}
+		if _, ok := upcasters[upcaster.FromVersion]; ok {
+			return nil, fmt.Errorf("upcaster of event type [%s::%s] from version [%d] is declared twice",
+				streamType, eventType.EventType, upcaster.FromVersion)
+		}
+
+		patch, err := jsonpatch.DecodePatch(upcaster.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse upcaster of event type [%s::%s] from version [%d]: %w",
+				streamType, eventType.EventType, upcaster.FromVersion, err)
+		}
+		upcasters[upcaster.FromVersion] = patch
+	}
+
+	return upcasters, nil
+}
+
+// Upcast converts the payloads of older schema versions with the upcasters of the event types, one version at a time.
+// Upcasting of an event stops at the current version, or at a version without an upcaster,
+// so that the rest of the chain can be applied by the client.
+// Shredded events, events of unregistered types and payloads that are not JSON are left as they are.
+func (r *Registry) Upcast(streamType string, events []estypes.Event) error {
+	eventTypes := r.streamTypes[streamType]
+
+	for i := range events {
+		event := &events[i]
+
+		eventType, ok := eventTypes[event.EventType]
+		if !ok || event.Shredded || !json.Valid([]byte(event.Payload)) {
+			continue
+		}
+
+		payload := []byte(event.Payload)
+		version := event.PayloadVersion()
+		for version < eventType.schemaVersion {
+			patch, ok := eventType.upcasters[version]
+			if !ok {
+				break
+			}
+
+			upcast, err := patch.ApplyWithOptions(payload, upcastOptions)
+			if err != nil {
+				return fmt.Errorf("failed to upcast event [%s::%d] of type [%s] from version [%d]: %w",
+					event.StreamId, event.Revision, event.EventType, version, err)
+			}
+
+			payload = upcast
+			version++
+		}
+
+		if version != event.PayloadVersion() {
+			event.Payload = string(payload)
+			event.SchemaVersion = version
+		}
+	}
+
+	return nil
+}
@@ -6,60 +6,102 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
 	"time"
 )
 
-func (r *EsRepo) AppendEvent(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvent estypes.NewEsEvent) (estypes.Stream, error) {
+// maxTransactItems is the limit of items in a single DynamoDB transaction.
+const maxTransactItems = 100
+
+func (r *EsRepo) AppendEvent(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvent estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error) {
+	return r.AppendEvents(ctx, streamType, streamId, revision, []estypes.NewEsEvent{newEvent}, options)
+}
+
+// AppendEvents persists events with consecutive revisions starting from the given one
+// together with the stream record update and the reservations of the options in a single transaction.
+func (r *EsRepo) AppendEvents(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvents []estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error) {
+	if len(newEvents) == 0 {
+		return estypes.Stream{}, fmt.Errorf("no events to append")
+	}
+	reservations := options.Reservations
+
+	// the stream record and the commit record of the global log
+	reservedItems := 2 + reservations.count()
+	if options.Idempotency != nil {
+		reservedItems++
+	}
+	if len(newEvents) > maxTransactItems-reservedItems {
+		return estypes.Stream{}, fmt.Errorf("too many events to append in one transaction: %d", len(newEvents))
+	}
+
 	now := time.Now()
 	stream := estypes.Stream{
 		StreamId:   streamId,
 		StreamType: streamType,
-		Revision:   revision,
+		Revision:   revision + len(newEvents) - 1,
 		UpdatedAt:  now,
 	}
-	event := estypes.NewEvent(streamId, revision, newEvent, now)
 
-	streamUpdate, err := prepareStreamUpdate(r.tableName, stream)
+	streamUpdate, err := prepareStreamUpdate(r.tableName, stream, revision-1, reservations)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	transactItems := make([]types.TransactWriteItem, 0, reservedItems)
+	transactItems = append(transactItems, types.TransactWriteItem{Update: streamUpdate})
+
+	dbEvents, err := r.prepareDbEvents(ctx, streamType, NewStreamEvents(streamId, revision, newEvents, now), options.EventTtl)
 	if err != nil {
 		return estypes.Stream{}, err
 	}
 
-	eventPut, err := PreparePutEventQuery(r.tableName, event)
+	if idempotency := options.Idempotency; idempotency != nil {
+		idempotencyPut, err := prepareIdempotencyPut(r.tableName, idempotency.Key, NewIdempotentResult(*idempotency, stream, now), now)
+		if err != nil {
+			return estypes.Stream{}, err
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Put: idempotencyPut})
+	}
+
+	reservationOffset := len(transactItems)
+	reservationItems, err := prepareReservationItems(r.tableName, reservations, stream, now)
 	if err != nil {
 		return estypes.Stream{}, err
 	}
+	transactItems = append(transactItems, reservationItems...)
 
-	_, err = r.dynamoDb.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
-		TransactItems: []types.TransactWriteItem{
-			{
-				Update: streamUpdate,
-			},
-			{
-				Put: eventPut,
-			},
-		},
-		ClientRequestToken: aws.String(uuid.NewString()), // todo: use better idempotency token; should come from client
-	})
+	err = r.transactEvents(ctx, transactItems, dbEvents, now)
 	if err != nil {
-		return estypes.Stream{}, fmt.Errorf("failed to complete DB transaction: %w", err)
+		err = fmt.Errorf("failed to complete DB transaction: %w", err)
+		return estypes.Stream{}, withReservationConflicts(err, reservationOffset, reservations)
 	}
 
 	return stream, nil
 }
 
-func prepareStreamUpdate(tableName string, stream estypes.Stream) (*types.Update, error) {
+// prepareStreamUpdate moves the stream record to the new revision, and tracks the claimed keys on it
+// instead of the released ones (see reservationAttr).
+func prepareStreamUpdate(tableName string, stream estypes.Stream, expectedRevision int, reservations Reservations) (*types.Update, error) {
 	updatedAtUtc := stream.UpdatedAt.UTC()
 
-	updateExpr, err := expression.NewBuilder().WithUpdate(
-		expression.
-			Set(expression.Name("StreamRevision"), expression.Value(stream.Revision)).
-			Set(expression.Name("UpdatedAt"), expression.Value(updatedAtUtc)),
-	).
-		WithCondition(expression.Name("StreamRevision").Equal(expression.Value(stream.Revision - 1))).
+	updateBuilder := expression.
+		Set(expression.Name("StreamRevision"), expression.Value(stream.Revision)).
+		Set(expression.Name("UpdatedAt"), expression.Value(updatedAtUtc))
+	for _, key := range reservations.Claim {
+		pk := ReservationPk(key)
+		updateBuilder = updateBuilder.Set(expression.Name(reservationAttr(pk)), expression.Value(pk))
+	}
+	for _, key := range reservations.Release {
+		updateBuilder = updateBuilder.Remove(expression.Name(reservationAttr(ReservationPk(key))))
+	}
+
+	updateExpr, err := expression.NewBuilder().WithUpdate(updateBuilder).
+		WithCondition(
+			expression.Name("StreamRevision").Equal(expression.Value(expectedRevision)).
+				And(expression.AttributeNotExists(expression.Name("Deleted"))),
+		).
 		Build()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build update expression: %w", err)
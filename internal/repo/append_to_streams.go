@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"time"
+)
+
+// StreamAppend is the part of a multi-stream write for one stream: events with consecutive revisions
+// starting from Revision, same as in AppendEvents. EventTtl is set on the new event records (see WriteOptions).
+type StreamAppend struct {
+	StreamType string
+	StreamId   uuid.UUID
+	Revision   int
+	Events     []estypes.NewEsEvent
+	EventTtl   time.Duration
+}
+
+// UpdatedStream is the stream record after the append.
+func (a StreamAppend) UpdatedStream(now time.Time) estypes.Stream {
+	return estypes.Stream{
+		StreamId:   a.StreamId,
+		StreamType: a.StreamType,
+		Revision:   a.Revision + len(a.Events) - 1,
+		UpdatedAt:  now,
+	}
+}
+
+func (a StreamAppend) Conflict() eserror.StreamConflict {
+	return eserror.StreamConflict{
+		StreamType: a.StreamType,
+		StreamId:   a.StreamId,
+	}
+}
+
+// CheckStreamAppends rejects a multi-stream write without events, with a stream appended to twice,
+// or with more items than fit into a single transaction (see estypes.MaxTransactionItems).
+func CheckStreamAppends(appends []StreamAppend) error {
+	if len(appends) == 0 {
+		return errors.New("no streams to append to")
+	}
+
+	items := 0
+	streamIds := make(map[uuid.UUID]bool, len(appends))
+	for _, a := range appends {
+		if len(a.Events) == 0 {
+			return fmt.Errorf("no events to append to stream [%s]", a.StreamId)
+		}
+		if streamIds[a.StreamId] {
+			return fmt.Errorf("stream [%s] is appended to twice", a.StreamId)
+		}
+		streamIds[a.StreamId] = true
+		items += 1 + len(a.Events)
+	}
+
+	if items > estypes.MaxTransactionItems {
+		return fmt.Errorf("too many items to write in one transaction: %d", items)
+	}
+
+	return nil
+}
+
+// AppendToStreams persists events to several streams in a single transaction, all or nothing.
+// Every stream record is updated under the condition of its expected revision, so every stream notifies its subscribers.
+//
+// When a stream is not at the expected revision, the transaction fails with eserror.DataConflictError
+// naming the conflicting streams. The result is remembered under the idempotency key unless idempotency is nil.
+func (r *EsRepo) AppendToStreams(ctx context.Context, appends []StreamAppend, idempotency *Idempotency) ([]estypes.Stream, error) {
+	err := CheckStreamAppends(appends)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	streams := make([]estypes.Stream, 0, len(appends))
+	// the stream updates go first, so that cancellation reasons of the transaction match the streams by index
+	transactItems := make([]types.TransactWriteItem, 0, len(appends))
+	var dbEvents []DbEvent
+
+	for _, a := range appends {
+		stream := a.UpdatedStream(now)
+
+		streamUpdate, err := prepareStreamUpdate(r.tableName, stream, a.Revision-1, Reservations{})
+		if err != nil {
+			return nil, err
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Update: streamUpdate})
+
+		streamEvents, err := r.prepareDbEvents(ctx, a.StreamType, NewStreamEvents(a.StreamId, a.Revision, a.Events, now), a.EventTtl)
+		if err != nil {
+			return nil, err
+		}
+		dbEvents = append(dbEvents, streamEvents...)
+
+		streams = append(streams, stream)
+	}
+
+	if idempotency != nil {
+		idempotencyPut, err := prepareIdempotencyPut(r.tableName, idempotency.Key, NewIdempotentStreamsResult(*idempotency, streams, now), now)
+		if err != nil {
+			return nil, err
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Put: idempotencyPut})
+	}
+
+	err = r.transactEvents(ctx, transactItems, dbEvents, now)
+	if err != nil {
+		err = fmt.Errorf("failed to complete DB transaction: %w", err)
+
+		conflicts := streamConflicts(err, appends)
+		if len(conflicts) > 0 {
+			return nil, eserror.NewStreamConflictError(err, conflicts...)
+		}
+		return nil, err
+	}
+
+	return streams, nil
+}
+
+// streamConflicts names the streams whose record update failed its condition.
+func streamConflicts(err error, appends []StreamAppend) []eserror.StreamConflict {
+	canceled := &types.TransactionCanceledException{}
+	if !errors.As(err, &canceled) {
+		return nil
+	}
+
+	var conflicts []eserror.StreamConflict
+	for i, reason := range canceled.CancellationReasons {
+		if i == len(appends) {
+			break
+		}
+		if aws.ToString(reason.Code) == conditionalCheckFailed {
+			conflicts = append(conflicts, appends[i].Conflict())
+		}
+	}
+
+	return conflicts
+}
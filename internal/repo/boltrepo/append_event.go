@@ -0,0 +1,80 @@
+package boltrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) AppendEvent(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvent estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	return r.AppendEvents(ctx, streamType, streamId, revision, []estypes.NewEsEvent{newEvent}, options)
+}
+
+func (r *BoltRepo) AppendEvents(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvents []estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	if len(newEvents) == 0 {
+		return estypes.Stream{}, fmt.Errorf("no events to append")
+	}
+
+	now := time.Now()
+	stream := estypes.Stream{
+		StreamId:   streamId,
+		StreamType: streamType,
+		Revision:   revision + len(newEvents) - 1,
+		UpdatedAt:  now,
+	}
+
+	dbEvents, err := r.prepareEvents(ctx, streamType, repo.NewStreamEvents(streamId, revision, newEvents, now))
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		current, err := loadStream(tx, streamId)
+		if err != nil {
+			return err
+		}
+
+		err = current.ShouldHaveRevision(revision - 1)
+		if err != nil {
+			return eserror.NewDataConflictError(err)
+		}
+
+		err = current.ShouldNotBeDeleted()
+		if err != nil {
+			return eserror.NewDataConflictError(err)
+		}
+
+		stored := current
+		stored.Revision = stream.Revision
+		stored.UpdatedAt = stream.UpdatedAt
+
+		err = saveStream(tx, stored, &current)
+		if err != nil {
+			return err
+		}
+
+		for _, dbEvent := range dbEvents {
+			err = putEvent(tx, streamId, dbEvent)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = applyReservations(tx, options.Reservations, stream, now)
+		if err != nil {
+			return err
+		}
+
+		return rememberResult(tx, options.Idempotency, stream, now)
+	})
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to complete DB transaction: %w", err)
+	}
+
+	return stream, nil
+}
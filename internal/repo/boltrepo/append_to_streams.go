@@ -0,0 +1,79 @@
+package boltrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+// AppendToStreams checks the revisions of all streams before writing any of them, in the same transaction.
+func (r *BoltRepo) AppendToStreams(ctx context.Context, appends []repo.StreamAppend, idempotency *repo.Idempotency) ([]estypes.Stream, error) {
+	err := repo.CheckStreamAppends(appends)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	streams := make([]estypes.Stream, 0, len(appends))
+	dbEvents := make([][]repo.DbEvent, 0, len(appends))
+	for _, a := range appends {
+		streamEvents, err := r.prepareEvents(ctx, a.StreamType, repo.NewStreamEvents(a.StreamId, a.Revision, a.Events, now))
+		if err != nil {
+			return nil, err
+		}
+
+		dbEvents = append(dbEvents, streamEvents)
+		streams = append(streams, a.UpdatedStream(now))
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		currentStreams := make([]estypes.Stream, 0, len(appends))
+		for _, a := range appends {
+			current, err := loadStream(tx, a.StreamId)
+			if err != nil {
+				return err
+			}
+
+			err = current.ShouldHaveRevision(a.Revision - 1)
+			if err != nil {
+				return eserror.NewStreamConflictError(err, a.Conflict())
+			}
+
+			err = current.ShouldNotBeDeleted()
+			if err != nil {
+				return eserror.NewStreamConflictError(err, a.Conflict())
+			}
+
+			currentStreams = append(currentStreams, current)
+		}
+
+		for i, current := range currentStreams {
+			stored := current
+			stored.Revision = streams[i].Revision
+			stored.UpdatedAt = streams[i].UpdatedAt
+
+			err := saveStream(tx, stored, &current)
+			if err != nil {
+				return err
+			}
+
+			for _, dbEvent := range dbEvents[i] {
+				err = putEvent(tx, current.StreamId, dbEvent)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return rememberStreamsResult(tx, idempotency, streams, now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete DB transaction: %w", err)
+	}
+
+	return streams, nil
+}
@@ -0,0 +1,66 @@
+package boltrepo
+
+import (
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+const defaultPageSize = 100
+
+var (
+	streamsBucket            = []byte("streams")
+	eventsBucket             = []byte("events")
+	streamIndexBucket        = []byte("stream-index")
+	idempotencyBucket        = []byte("idempotency")
+	snapshotsBucket          = []byte("snapshots")
+	allBucket                = []byte("all")
+	categoryBucket           = []byte("category-index")
+	reservationsBucket       = []byte("reservations")
+	streamReservationsBucket = []byte("stream-reservations")
+)
+
+// BoltRepo is a storage backend of the Event Store that keeps all data in a single embedded database file.
+//
+// Stream and event records have the same layout as in DynamoDB (see repo.DbStream and repo.DbEvent).
+// Writes run in serializable transactions with the same revision checks as the DynamoDB backend.
+type BoltRepo struct {
+	db       *bolt.DB
+	payloads repo.PayloadStorage
+	pageSize int
+}
+
+var _ repo.EsStore = (*BoltRepo)(nil)
+
+// NewBoltRepo opens or creates the database file.
+func NewBoltRepo(path string, payloads repo.PayloadStorage) (*BoltRepo, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database file [%s]: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{streamsBucket, eventsBucket, streamIndexBucket, idempotencyBucket, snapshotsBucket, allBucket, categoryBucket, reservationsBucket, streamReservationsBucket} {
+			_, err := tx.CreateBucketIfNotExists(bucket)
+			if err != nil {
+				return fmt.Errorf("failed to create bucket [%s]: %w", bucket, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltRepo{
+		db:       db,
+		payloads: payloads,
+		pageSize: defaultPageSize,
+	}, nil
+}
+
+func (r *BoltRepo) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,66 @@
+package boltrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) CreateStream(ctx context.Context, streamType string, initialEvent estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	return r.CreateStreamWithId(ctx, streamType, uuid.New(), initialEvent, options)
+}
+
+func (r *BoltRepo) CreateStreamWithId(ctx context.Context, streamType string, streamId uuid.UUID, initialEvent estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	return r.CreateStreamWithEvents(ctx, streamType, streamId, []estypes.NewEsEvent{initialEvent}, options)
+}
+
+func (r *BoltRepo) CreateStreamWithEvents(ctx context.Context, streamType string, streamId uuid.UUID, newEvents []estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	if len(newEvents) == 0 {
+		return estypes.Stream{}, fmt.Errorf("no events to create stream with")
+	}
+
+	now := time.Now()
+	stream := estypes.NewStream(streamId, streamType, now)
+	stream.Revision = len(newEvents)
+
+	dbEvents, err := r.prepareEvents(ctx, streamType, repo.NewStreamEvents(streamId, 1, newEvents, now))
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(streamsBucket).Get(streamKey(streamId)) != nil {
+			err := fmt.Errorf("stream already exists [%s]", streamId)
+			return eserror.NewDataConflictError(err)
+		}
+
+		err := saveStream(tx, stream, nil)
+		if err != nil {
+			return err
+		}
+
+		for _, dbEvent := range dbEvents {
+			err = putEvent(tx, streamId, dbEvent)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = applyReservations(tx, options.Reservations, stream, now)
+		if err != nil {
+			return err
+		}
+
+		return rememberResult(tx, options.Idempotency, stream, now)
+	})
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to create stream: %w", err)
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,101 @@
+package boltrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+	"time"
+)
+
+func (r *BoltRepo) DeleteStream(ctx context.Context, streamId uuid.UUID, deletion string) (estypes.Stream, error) {
+	var stream estypes.Stream
+	var payloadRefs []string
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		current, err := loadStream(tx, streamId)
+		if err != nil {
+			return err
+		}
+
+		if deletion == estypes.DeletionSoft {
+			err = current.ShouldAllowSoftDeletion()
+			if err != nil {
+				return eserror.NewDataConflictError(err)
+			}
+		}
+
+		stream = current
+		stream.Deleted = deletion
+		stream.UpdatedAt = time.Now()
+
+		err = saveStream(tx, stream, &current)
+		if err != nil {
+			return err
+		}
+
+		err = releaseStreamReservations(tx, streamId)
+		if err != nil {
+			return err
+		}
+
+		if deletion != estypes.DeletionHard {
+			return nil
+		}
+
+		payloadRefs, err = purgeStream(tx, streamId)
+		return err
+	})
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to complete DB transaction: %w", err)
+	}
+
+	err = r.payloads.Discard(ctx, streamId, payloadRefs)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	return stream, nil
+}
+
+// purgeStream deletes the events and the snapshot of the stream and returns the keys of offloaded payloads.
+func purgeStream(tx *bolt.Tx, streamId uuid.UUID) ([]string, error) {
+	var payloadRefs []string
+
+	streamEvents := tx.Bucket(eventsBucket).Bucket(streamKey(streamId))
+	if streamEvents != nil {
+		err := streamEvents.ForEach(func(_, value []byte) error {
+			var dbEvent struct {
+				PayloadRef string
+			}
+			err := json.Unmarshal(value, &dbEvent)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal event from DB: %w", err)
+			}
+			if dbEvent.PayloadRef != "" {
+				payloadRefs = append(payloadRefs, dbEvent.PayloadRef)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := tx.Bucket(eventsBucket).DeleteBucket(streamKey(streamId))
+	if err != nil && !errors.Is(err, bolterrors.ErrBucketNotFound) {
+		return nil, fmt.Errorf("failed to delete events: %w", err)
+	}
+
+	err = tx.Bucket(snapshotsBucket).Delete(streamKey(streamId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	return payloadRefs, nil
+}
@@ -0,0 +1,73 @@
+package boltrepo
+
+import (
+	"context"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) GetAllEvents(ctx context.Context, afterPosition int, limit int) (estypes.AllEventPage, error) {
+	events := make([]estypes.Event, 0)
+	lastEvaluatedPosition := max(afterPosition, 0)
+	hasMore := false
+
+	pageSize := r.pageSize
+	if limit > 0 {
+		pageSize = min(pageSize, limit)
+	}
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(allBucket).Cursor()
+		streams := make(streamCache)
+		now := time.Now()
+
+		evaluated := 0
+		for key, value := cursor.Seek(revisionKey(lastEvaluatedPosition + 1)); key != nil; key, value = cursor.Next() {
+			if evaluated == pageSize {
+				hasMore = true
+				break
+			}
+
+			evaluated++
+			lastEvaluatedPosition = revisionFromKey(key)
+
+			streamId, revision := eventRefFromValue(value)
+			streamEvents := tx.Bucket(eventsBucket).Bucket(streamKey(streamId))
+			if streamEvents == nil {
+				continue
+			}
+			eventValue := streamEvents.Get(revisionKey(revision))
+			if eventValue == nil {
+				continue
+			}
+
+			event, err := r.decodeEvent(ctx, eventValue)
+			if err != nil {
+				return err
+			}
+			stream, err := streams.load(tx, streamId)
+			if err != nil {
+				return err
+			}
+			if !stream.Serves(event, now) {
+				continue
+			}
+			event.StreamType = stream.StreamType
+			events = append(events, event)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return estypes.AllEventPage{}, err
+	}
+
+	page := estypes.AllEventPage{
+		Events:                events,
+		HasMore:               hasMore,
+		LastEvaluatedPosition: lastEvaluatedPosition,
+	}
+
+	return page, nil
+}
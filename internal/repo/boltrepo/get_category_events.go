@@ -0,0 +1,90 @@
+package boltrepo
+
+import (
+	"bytes"
+	"context"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) GetCategoryEvents(ctx context.Context, streamType string, createdAfter time.Time, nextPageKey string, limit int) (estypes.CategoryEventPage, error) {
+	if nextPageKey != "" {
+		_, _, err := repo.ParseCategoryKey(nextPageKey)
+		if err != nil {
+			return estypes.CategoryEventPage{}, err
+		}
+	}
+
+	events := make([]estypes.Event, 0)
+	hasMore := false
+
+	pageSize := r.pageSize
+	if limit > 0 {
+		pageSize = min(pageSize, limit)
+	}
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		prefix := streamTypePrefix(streamType)
+		startKey, isNextPageKey := repo.CategoryStartKey(createdAfter, nextPageKey)
+		cursor := tx.Bucket(categoryBucket).Cursor()
+		streams := make(streamCache)
+		now := time.Now()
+
+		evaluated := 0
+		for key, value := cursor.Seek(categoryIndexKey(streamType, startKey)); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			categoryKey := string(key[len(prefix):])
+			if isNextPageKey && categoryKey == startKey {
+				continue
+			}
+
+			if evaluated == pageSize {
+				hasMore = true
+				break
+			}
+
+			evaluated++
+			nextPageKey = categoryKey
+
+			streamId, revision := eventRefFromValue(value)
+			streamEvents := tx.Bucket(eventsBucket).Bucket(streamKey(streamId))
+			if streamEvents == nil {
+				continue
+			}
+			eventValue := streamEvents.Get(revisionKey(revision))
+			if eventValue == nil {
+				continue
+			}
+
+			event, err := r.decodeEvent(ctx, eventValue)
+			if err != nil {
+				return err
+			}
+			stream, err := streams.load(tx, streamId)
+			if err != nil {
+				return err
+			}
+			if !stream.Serves(event, now) {
+				continue
+			}
+			event.StreamType = stream.StreamType
+			events = append(events, event)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return estypes.CategoryEventPage{}, err
+	}
+
+	page := estypes.CategoryEventPage{
+		Events:  events,
+		HasMore: hasMore,
+	}
+	if nextPageKey != "" {
+		page.NextPageKey = &nextPageKey
+	}
+
+	return page, nil
+}
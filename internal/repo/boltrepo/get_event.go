@@ -0,0 +1,35 @@
+package boltrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (r *BoltRepo) GetEvent(ctx context.Context, streamId uuid.UUID, revision int) (estypes.Event, error) {
+	var event estypes.Event
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var value []byte
+		streamEvents := tx.Bucket(eventsBucket).Bucket(streamKey(streamId))
+		if streamEvents != nil && revision > 0 {
+			value = streamEvents.Get(revisionKey(revision))
+		}
+		if value == nil {
+			err := fmt.Errorf("event not found [%s::%d]", streamId, revision)
+			return eserror.NewNotFoundError(err)
+		}
+
+		var err error
+		event, err = r.decodeEvent(ctx, value)
+		return err
+	})
+	if err != nil {
+		return estypes.Event{}, err
+	}
+
+	return event, nil
+}
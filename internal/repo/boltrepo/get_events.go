@@ -0,0 +1,109 @@
+package boltrepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) GetEvents(ctx context.Context, streamId uuid.UUID, eventRange repo.EventRange) (estypes.EventPage, error) {
+	events := make([]estypes.Event, 0)
+	var lastEvaluatedRevision int
+	hasMore := false
+
+	pageSize := r.pageSize
+	if eventRange.Limit > 0 {
+		pageSize = min(pageSize, eventRange.Limit)
+	}
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		streamEvents := tx.Bucket(eventsBucket).Bucket(streamKey(streamId))
+		if streamEvents == nil {
+			return nil
+		}
+
+		stream, err := loadStream(tx, streamId)
+		if err != nil {
+			return err
+		}
+		lowest, highest := eventRange.Revisions(stream)
+		now := time.Now()
+
+		cursor := streamEvents.Cursor()
+		first, next := cursor.First, cursor.Next
+		if eventRange.Backward {
+			first, next = cursor.Last, cursor.Prev
+		}
+
+		evaluated := 0
+		for key, value := seekRevision(cursor, eventRange, lowest, highest, first); key != nil; key, value = next() {
+			revision := revisionFromKey(key)
+			if revision < lowest || revision > highest {
+				break
+			}
+
+			if evaluated == pageSize {
+				hasMore = true
+				break
+			}
+
+			evaluated++
+			lastEvaluatedRevision = revision
+
+			eventType, err := decodeEventType(value)
+			if err != nil {
+				return err
+			}
+			if !eventRange.Selects(eventType) {
+				continue
+			}
+
+			event, err := r.decodeEvent(ctx, value)
+			if err != nil {
+				return err
+			}
+
+			if stream.Metadata.IsExpired(event.CreatedAt, now) {
+				continue
+			}
+			events = append(events, event)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return estypes.EventPage{}, err
+	}
+
+	page := estypes.EventPage{
+		Events:                events,
+		HasMore:               hasMore,
+		LastEvaluatedRevision: lastEvaluatedRevision,
+	}
+
+	return page, nil
+}
+
+// seekRevision moves the cursor to the first event of the range in the reading direction.
+func seekRevision(cursor *bolt.Cursor, eventRange repo.EventRange, lowest int, highest int, first func() ([]byte, []byte)) ([]byte, []byte) {
+	if lowest > highest {
+		return nil, nil
+	}
+
+	if !eventRange.Backward {
+		return cursor.Seek(revisionKey(lowest))
+	}
+
+	key, value := cursor.Seek(revisionKey(highest))
+	if key == nil {
+		return first()
+	}
+	if revisionFromKey(key) > highest {
+		return cursor.Prev()
+	}
+
+	return key, value
+}
@@ -0,0 +1,23 @@
+package boltrepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (r *BoltRepo) GetStream(_ context.Context, streamId uuid.UUID) (estypes.Stream, error) {
+	var stream estypes.Stream
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		stream, err = loadStream(tx, streamId)
+		return err
+	})
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	return stream, nil
+}
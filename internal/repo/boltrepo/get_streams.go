@@ -0,0 +1,75 @@
+package boltrepo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) GetStreams(_ context.Context, streamType string, updatedAfter time.Time, streamNextPageKey string) (estypes.StreamPage, error) {
+	seekKey := streamIndexPrefix(streamType, updatedAfter)
+	var afterKey []byte
+	if streamNextPageKey != "" {
+		cursor, err := repo.ParseStreamCursor(streamNextPageKey)
+		if err != nil {
+			return estypes.StreamPage{}, fmt.Errorf("failed to parse next page key: %w", err)
+		}
+		afterKey = streamIndexKey(streamType, cursor.UpdatedAt, cursor.StreamId)
+		if bytes.Compare(afterKey, seekKey) > 0 {
+			seekKey = afterKey
+		}
+	}
+
+	streams := make([]estypes.Stream, 0)
+	hasMore := false
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		typePrefix := streamTypePrefix(streamType)
+		streamsRecords := tx.Bucket(streamsBucket)
+
+		cursor := tx.Bucket(streamIndexBucket).Cursor()
+		for key, value := cursor.Seek(seekKey); key != nil && bytes.HasPrefix(key, typePrefix); key, value = cursor.Next() {
+			if bytes.Equal(key, afterKey) {
+				continue
+			}
+
+			if len(streams) == r.pageSize {
+				hasMore = true
+				break
+			}
+
+			streamValue := streamsRecords.Get(value)
+			if streamValue == nil {
+				return fmt.Errorf("stream index points to missing stream [%s]", uuid.UUID(value))
+			}
+
+			stream, err := decodeStream(streamValue)
+			if err != nil {
+				return err
+			}
+
+			streams = append(streams, stream)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return estypes.StreamPage{}, err
+	}
+
+	page := estypes.StreamPage{
+		Streams: streams,
+		HasMore: hasMore,
+	}
+	if hasMore {
+		newNextPageKey := repo.NewStreamCursor(streams[len(streams)-1]).String()
+		page.NextPageKey = &newNextPageKey
+	}
+
+	return page, nil
+}
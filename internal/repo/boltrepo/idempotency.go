@@ -0,0 +1,100 @@
+package boltrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) GetIdempotentResult(_ context.Context, key string) (repo.IdempotentResult, error) {
+	var result repo.IdempotentResult
+	var exists bool
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		result, exists, err = loadIdempotentResult(tx, key)
+		return err
+	})
+	if err != nil {
+		return repo.IdempotentResult{}, err
+	}
+
+	if !exists || result.IsExpired(time.Now()) {
+		err = fmt.Errorf("idempotent result not found")
+		return repo.IdempotentResult{}, eserror.NewNotFoundError(err)
+	}
+
+	return result, nil
+}
+
+func loadIdempotentResult(tx *bolt.Tx, key string) (repo.IdempotentResult, bool, error) {
+	value := tx.Bucket(idempotencyBucket).Get([]byte(key))
+	if value == nil {
+		return repo.IdempotentResult{}, false, nil
+	}
+
+	var dbIdempotency repo.DbIdempotency
+	err := json.Unmarshal(value, &dbIdempotency)
+	if err != nil {
+		return repo.IdempotentResult{}, false, fmt.Errorf("failed to unmarshal idempotent result from DB: %w", err)
+	}
+
+	result, err := repo.IntoIdempotentResult(dbIdempotency)
+	if err != nil {
+		return repo.IdempotentResult{}, false, err
+	}
+
+	return result, true, nil
+}
+
+// rememberResult stores the result of the write in the same transaction,
+// unless the idempotency key is already taken by another write.
+func rememberResult(tx *bolt.Tx, idempotency *repo.Idempotency, stream estypes.Stream, now time.Time) error {
+	if idempotency == nil {
+		return nil
+	}
+
+	return putIdempotentResult(tx, idempotency.Key, repo.NewIdempotentResult(*idempotency, stream, now), now)
+}
+
+// rememberStreamsResult is rememberResult of a multi-stream write.
+func rememberStreamsResult(tx *bolt.Tx, idempotency *repo.Idempotency, streams []estypes.Stream, now time.Time) error {
+	if idempotency == nil {
+		return nil
+	}
+
+	return putIdempotentResult(tx, idempotency.Key, repo.NewIdempotentStreamsResult(*idempotency, streams, now), now)
+}
+
+func putIdempotentResult(tx *bolt.Tx, key string, result repo.IdempotentResult, now time.Time) error {
+	existing, exists, err := loadIdempotentResult(tx, key)
+	if err != nil {
+		return err
+	}
+	if exists && !existing.IsExpired(now) {
+		err = fmt.Errorf("idempotency key already used [%s]", key)
+		return eserror.NewDataConflictError(err)
+	}
+
+	dbIdempotency, err := repo.FromIdempotentResult(key, result)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(dbIdempotency)
+	if err != nil {
+		return fmt.Errorf("failed to marshal db idempotency: %w", err)
+	}
+
+	err = tx.Bucket(idempotencyBucket).Put([]byte(key), value)
+	if err != nil {
+		return fmt.Errorf("failed to put idempotent result: %w", err)
+	}
+
+	return nil
+}
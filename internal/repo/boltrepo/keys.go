@@ -0,0 +1,52 @@
+package boltrepo
+
+import (
+	"encoding/binary"
+	"github.com/google/uuid"
+	"time"
+)
+
+func streamKey(streamId uuid.UUID) []byte {
+	return streamId[:]
+}
+
+func revisionKey(revision int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(revision))
+}
+
+func revisionFromKey(key []byte) int {
+	return int(binary.BigEndian.Uint64(key))
+}
+
+// streamIndexPrefix makes keys of the stream index sorted by stream type and then by update time,
+// same as StreamIndexV2 in DynamoDB.
+func streamIndexPrefix(streamType string, updatedAt time.Time) []byte {
+	key := make([]byte, 0, len(streamType)+1+8+16)
+	key = append(key, streamType...)
+	key = append(key, 0)
+	key = binary.BigEndian.AppendUint64(key, uint64(updatedAt.UnixNano())^(1<<63))
+
+	return key
+}
+
+func streamIndexKey(streamType string, updatedAt time.Time, streamId uuid.UUID) []byte {
+	return append(streamIndexPrefix(streamType, updatedAt), streamId[:]...)
+}
+
+func streamTypePrefix(streamType string) []byte {
+	return append([]byte(streamType), 0)
+}
+
+// eventRefValue refers to an event from the global log.
+func eventRefValue(streamId uuid.UUID, revision int) []byte {
+	return append(streamKey(streamId), revisionKey(revision)...)
+}
+
+func eventRefFromValue(value []byte) (uuid.UUID, int) {
+	return uuid.UUID(value[:16]), revisionFromKey(value[16:])
+}
+
+// categoryIndexKey sorts the events of a stream type by repo.CategoryKey, same as CategoryIndex in DynamoDB.
+func categoryIndexKey(streamType string, categoryKey string) []byte {
+	return append(streamTypePrefix(streamType), categoryKey...)
+}
@@ -0,0 +1,186 @@
+package boltrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+)
+
+func loadStream(tx *bolt.Tx, streamId uuid.UUID) (estypes.Stream, error) {
+	value := tx.Bucket(streamsBucket).Get(streamKey(streamId))
+	if value == nil {
+		err := fmt.Errorf("stream not found")
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	return decodeStream(value)
+}
+
+// streamCache keeps the stream records loaded within a transaction, e.g. for the events of a page.
+type streamCache map[uuid.UUID]estypes.Stream
+
+func (c streamCache) load(tx *bolt.Tx, streamId uuid.UUID) (estypes.Stream, error) {
+	stream, ok := c[streamId]
+	if ok {
+		return stream, nil
+	}
+
+	stream, err := loadStream(tx, streamId)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+	c[streamId] = stream
+
+	return stream, nil
+}
+
+func decodeStream(value []byte) (estypes.Stream, error) {
+	var dbStream repo.DbStream
+	err := json.Unmarshal(value, &dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to unmarshal stream from DB: %w", err)
+	}
+
+	stream, err := repo.IntoStream(dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to convert DbStream into Stream [%s]: %w", dbStream.Pk, err)
+	}
+
+	return stream, nil
+}
+
+// saveStream writes the stream record and moves it in the stream index.
+// previous is nil for a new stream.
+func saveStream(tx *bolt.Tx, stream estypes.Stream, previous *estypes.Stream) error {
+	value, err := json.Marshal(repo.FromStream(stream))
+	if err != nil {
+		return fmt.Errorf("failed to marshal db stream: %w", err)
+	}
+
+	err = tx.Bucket(streamsBucket).Put(streamKey(stream.StreamId), value)
+	if err != nil {
+		return fmt.Errorf("failed to put stream: %w", err)
+	}
+
+	streamIndex := tx.Bucket(streamIndexBucket)
+	if previous != nil {
+		err = streamIndex.Delete(streamIndexKey(previous.StreamType, previous.UpdatedAt, previous.StreamId))
+		if err != nil {
+			return fmt.Errorf("failed to delete stream index entry: %w", err)
+		}
+	}
+
+	err = streamIndex.Put(streamIndexKey(stream.StreamType, stream.UpdatedAt, stream.StreamId), streamKey(stream.StreamId))
+	if err != nil {
+		return fmt.Errorf("failed to put stream index entry: %w", err)
+	}
+
+	return nil
+}
+
+// prepareEvents builds the event records of a write, offloading or compressing the payloads.
+// It should be called before the write transaction, so that the blob store is not accessed under the database lock.
+func (r *BoltRepo) prepareEvents(ctx context.Context, streamType string, events []estypes.Event) ([]repo.DbEvent, error) {
+	dbEvents := make([]repo.DbEvent, 0, len(events))
+	for _, event := range events {
+		dbEvents = append(dbEvents, repo.FromCategoryEvent(streamType, event))
+	}
+
+	err := r.payloads.Store(ctx, dbEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbEvents, nil
+}
+
+func putEvent(tx *bolt.Tx, streamId uuid.UUID, dbEvent repo.DbEvent) error {
+	streamEvents, err := tx.Bucket(eventsBucket).CreateBucketIfNotExists(streamKey(streamId))
+	if err != nil {
+		return fmt.Errorf("failed to create stream events bucket: %w", err)
+	}
+
+	key := revisionKey(dbEvent.Sk)
+	if streamEvents.Get(key) != nil {
+		err = fmt.Errorf("event already exists [%s::%d]", dbEvent.Pk, dbEvent.Sk)
+		return eserror.NewDataConflictError(err)
+	}
+
+	dbEvent.Position, err = appendToAll(tx, streamId, dbEvent.Sk)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Bucket(categoryBucket).Put(categoryIndexKey(dbEvent.StreamType, dbEvent.CategoryKey), eventRefValue(streamId, dbEvent.Sk))
+	if err != nil {
+		return fmt.Errorf("failed to put category index entry: %w", err)
+	}
+
+	value, err := json.Marshal(dbEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal db event: %w", err)
+	}
+
+	err = streamEvents.Put(key, value)
+	if err != nil {
+		return fmt.Errorf("failed to put event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BoltRepo) decodeEvent(ctx context.Context, value []byte) (estypes.Event, error) {
+	var dbEvent repo.DbEvent
+	err := json.Unmarshal(value, &dbEvent)
+	if err != nil {
+		return estypes.Event{}, fmt.Errorf("failed to unmarshal event from DB: %w", err)
+	}
+
+	err = r.payloads.Load(ctx, &dbEvent)
+	if err != nil {
+		return estypes.Event{}, err
+	}
+
+	event, err := repo.IntoEvent(dbEvent)
+	if err != nil {
+		return estypes.Event{}, fmt.Errorf("failed to convert DbEvent into Event [%s::%d]: %w", dbEvent.Pk, dbEvent.Sk, err)
+	}
+
+	return event, nil
+}
+
+// appendToAll takes the next position of the global log for the event.
+// The sequence of the bucket is rolled back together with a failed transaction, so positions have no gaps.
+func appendToAll(tx *bolt.Tx, streamId uuid.UUID, revision int) (int, error) {
+	all := tx.Bucket(allBucket)
+
+	position, err := all.NextSequence()
+	if err != nil {
+		return 0, fmt.Errorf("failed to take position in global log: %w", err)
+	}
+
+	err = all.Put(revisionKey(int(position)), eventRefValue(streamId, revision))
+	if err != nil {
+		return 0, fmt.Errorf("failed to put global log entry: %w", err)
+	}
+
+	return int(position), nil
+}
+
+// decodeEventType reads only the type of the event, so that filtered out events skip loading the payload.
+func decodeEventType(value []byte) (string, error) {
+	var dbEvent struct {
+		EventType string
+	}
+	err := json.Unmarshal(value, &dbEvent)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal event type from DB: %w", err)
+	}
+
+	return dbEvent.EventType, nil
+}
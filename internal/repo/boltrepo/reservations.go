@@ -0,0 +1,147 @@
+package boltrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+func (r *BoltRepo) GetReservation(_ context.Context, key estypes.ReservationKey) (estypes.Reservation, error) {
+	var reservation estypes.Reservation
+	var exists bool
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		reservation, exists, err = loadReservation(tx, key)
+		return err
+	})
+	if err != nil {
+		return estypes.Reservation{}, err
+	}
+
+	if !exists {
+		err = fmt.Errorf("reservation not found [%s::%s]", key.Namespace, key.Key)
+		return estypes.Reservation{}, eserror.NewNotFoundError(err)
+	}
+
+	return reservation, nil
+}
+
+func loadReservation(tx *bolt.Tx, key estypes.ReservationKey) (estypes.Reservation, bool, error) {
+	value := tx.Bucket(reservationsBucket).Get([]byte(repo.ReservationPk(key)))
+	if value == nil {
+		return estypes.Reservation{}, false, nil
+	}
+
+	var dbReservation repo.DbReservation
+	err := json.Unmarshal(value, &dbReservation)
+	if err != nil {
+		return estypes.Reservation{}, false, fmt.Errorf("failed to unmarshal reservation from DB: %w", err)
+	}
+
+	reservation, err := repo.IntoReservation(dbReservation)
+	if err != nil {
+		return estypes.Reservation{}, false, err
+	}
+
+	return reservation, true, nil
+}
+
+// applyReservations claims and releases the keys in the same transaction as the write,
+// once none of them is held by another stream.
+func applyReservations(tx *bolt.Tx, reservations repo.Reservations, stream estypes.Stream, now time.Time) error {
+	var conflicts []eserror.ReservationConflict
+	for _, key := range reservations.Claim {
+		existing, exists, err := loadReservation(tx, key)
+		if err != nil {
+			return err
+		}
+		if exists && existing.StreamId != stream.StreamId {
+			conflicts = append(conflicts, eserror.ReservationConflict{Namespace: key.Namespace, Key: key.Key})
+		}
+	}
+	for _, key := range reservations.Release {
+		existing, exists, err := loadReservation(tx, key)
+		if err != nil {
+			return err
+		}
+		if !exists || existing.StreamId != stream.StreamId {
+			conflicts = append(conflicts, eserror.ReservationConflict{Namespace: key.Namespace, Key: key.Key})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		err := fmt.Errorf("reservations are held by other streams [%s]", stream.StreamId)
+		return eserror.NewReservationConflictError(err, conflicts...)
+	}
+
+	bucket := tx.Bucket(reservationsBucket)
+	held, err := tx.Bucket(streamReservationsBucket).CreateBucketIfNotExists(streamKey(stream.StreamId))
+	if err != nil {
+		return fmt.Errorf("failed to create stream reservations bucket: %w", err)
+	}
+	for _, key := range reservations.Claim {
+		value, err := json.Marshal(repo.FromReservation(estypes.NewReservation(key, stream, now)))
+		if err != nil {
+			return fmt.Errorf("failed to marshal db reservation: %w", err)
+		}
+
+		pk := []byte(repo.ReservationPk(key))
+		err = bucket.Put(pk, value)
+		if err != nil {
+			return fmt.Errorf("failed to put reservation: %w", err)
+		}
+		err = held.Put(pk, []byte{})
+		if err != nil {
+			return fmt.Errorf("failed to put stream reservation: %w", err)
+		}
+	}
+	for _, key := range reservations.Release {
+		pk := []byte(repo.ReservationPk(key))
+		err := bucket.Delete(pk)
+		if err != nil {
+			return fmt.Errorf("failed to delete reservation: %w", err)
+		}
+		err = held.Delete(pk)
+		if err != nil {
+			return fmt.Errorf("failed to delete stream reservation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// releaseStreamReservations deletes the reservations held by the deleted stream,
+// as listed in its bucket of stream-reservations.
+func releaseStreamReservations(tx *bolt.Tx, streamId uuid.UUID) error {
+	streamReservations := tx.Bucket(streamReservationsBucket)
+	held := streamReservations.Bucket(streamKey(streamId))
+	if held == nil {
+		return nil
+	}
+
+	bucket := tx.Bucket(reservationsBucket)
+	err := held.ForEach(func(pk, _ []byte) error {
+		err := bucket.Delete(pk)
+		if err != nil {
+			return fmt.Errorf("failed to delete reservation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = streamReservations.DeleteBucket(streamKey(streamId))
+	if err != nil {
+		return fmt.Errorf("failed to delete stream reservations bucket: %w", err)
+	}
+
+	return nil
+}
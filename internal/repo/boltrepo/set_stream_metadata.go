@@ -0,0 +1,36 @@
+package boltrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (r *BoltRepo) SetStreamMetadata(_ context.Context, streamId uuid.UUID, metadata estypes.StreamMetadata) (estypes.Stream, error) {
+	var stream estypes.Stream
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		current, err := loadStream(tx, streamId)
+		if err != nil {
+			return err
+		}
+
+		err = current.ShouldNotBeDeleted()
+		if err != nil {
+			return eserror.NewDataConflictError(err)
+		}
+
+		stream = current
+		stream.Metadata = &metadata
+
+		return saveStream(tx, stream, &current)
+	})
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to complete DB transaction: %w", err)
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,23 @@
+package boltrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (r *BoltRepo) ShredPayloads(ctx context.Context, streamId uuid.UUID) error {
+	err := r.payloads.Shred(ctx, streamId)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(snapshotsBucket).Delete(streamKey(streamId))
+		if err != nil {
+			return fmt.Errorf("failed to delete snapshot: %w", err)
+		}
+		return nil
+	})
+}
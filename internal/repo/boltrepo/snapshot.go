@@ -0,0 +1,78 @@
+package boltrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (r *BoltRepo) SaveSnapshot(_ context.Context, snapshot estypes.Snapshot) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		existing, exists, err := loadSnapshot(tx, snapshot.StreamId)
+		if err != nil {
+			return err
+		}
+		if exists && existing.Revision > snapshot.Revision {
+			err = fmt.Errorf("stream [%s] already has a snapshot of a later revision", snapshot.StreamId)
+			return eserror.NewDataConflictError(err)
+		}
+
+		value, err := json.Marshal(repo.FromSnapshot(snapshot))
+		if err != nil {
+			return fmt.Errorf("failed to marshal db snapshot: %w", err)
+		}
+
+		err = tx.Bucket(snapshotsBucket).Put(streamKey(snapshot.StreamId), value)
+		if err != nil {
+			return fmt.Errorf("failed to put snapshot: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *BoltRepo) GetSnapshot(_ context.Context, streamId uuid.UUID) (estypes.Snapshot, error) {
+	var snapshot estypes.Snapshot
+	var exists bool
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		snapshot, exists, err = loadSnapshot(tx, streamId)
+		return err
+	})
+	if err != nil {
+		return estypes.Snapshot{}, err
+	}
+
+	if !exists {
+		err = fmt.Errorf("snapshot not found")
+		return estypes.Snapshot{}, eserror.NewNotFoundError(err)
+	}
+
+	return snapshot, nil
+}
+
+func loadSnapshot(tx *bolt.Tx, streamId uuid.UUID) (estypes.Snapshot, bool, error) {
+	value := tx.Bucket(snapshotsBucket).Get(streamKey(streamId))
+	if value == nil {
+		return estypes.Snapshot{}, false, nil
+	}
+
+	var dbSnapshot repo.DbSnapshot
+	err := json.Unmarshal(value, &dbSnapshot)
+	if err != nil {
+		return estypes.Snapshot{}, false, fmt.Errorf("failed to unmarshal snapshot from DB: %w", err)
+	}
+
+	snapshot, err := repo.IntoSnapshot(dbSnapshot)
+	if err != nil {
+		return estypes.Snapshot{}, false, fmt.Errorf("failed to convert DbSnapshot into Snapshot [%s]: %w", streamId, err)
+	}
+
+	return snapshot, true, nil
+}
@@ -0,0 +1,78 @@
+package boltrepo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (r *BoltRepo) TruncateStream(ctx context.Context, streamId uuid.UUID, beforeRevision int) (estypes.Stream, error) {
+	var stream estypes.Stream
+	var payloadRefs []string
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		current, err := loadStream(tx, streamId)
+		if err != nil {
+			return err
+		}
+
+		err = current.ShouldAllowTruncation(beforeRevision)
+		if err != nil {
+			return eserror.NewDataConflictError(err)
+		}
+
+		stream = current
+		stream.TruncatedBefore = beforeRevision
+
+		err = saveStream(tx, stream, &current)
+		if err != nil {
+			return err
+		}
+
+		streamEvents := tx.Bucket(eventsBucket).Bucket(streamKey(streamId))
+		if streamEvents == nil {
+			return nil
+		}
+
+		// keys are collected first, since deleting under a cursor makes it skip the next key
+		var truncatedKeys [][]byte
+		cursor := streamEvents.Cursor()
+		for key, value := cursor.First(); key != nil && revisionFromKey(key) < beforeRevision; key, value = cursor.Next() {
+			var dbEvent struct {
+				PayloadRef string
+			}
+			err = json.Unmarshal(value, &dbEvent)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal event from DB: %w", err)
+			}
+			if dbEvent.PayloadRef != "" {
+				payloadRefs = append(payloadRefs, dbEvent.PayloadRef)
+			}
+			truncatedKeys = append(truncatedKeys, bytes.Clone(key))
+		}
+
+		for _, key := range truncatedKeys {
+			err = streamEvents.Delete(key)
+			if err != nil {
+				return fmt.Errorf("failed to delete event: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to complete DB transaction: %w", err)
+	}
+
+	err = r.payloads.Offload.Discard(ctx, payloadRefs)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const categoryIndexName = "CategoryIndex"
+
+// categoryTimeLayout has a fixed width, so that keys sort in the order of time.
+const categoryTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// CategoryKey orders the events of a stream type by creation time and then by revision,
+// the stream id makes the key unique. It is also the next page key of a category read.
+func CategoryKey(createdAt time.Time, revision int, streamId uuid.UUID) string {
+	return fmt.Sprintf("%s#%010d#%s", categoryTimeKey(createdAt), revision, streamId)
+}
+
+// categoryTimeKey sorts before the keys of all events created at the time or later.
+func categoryTimeKey(createdAt time.Time) string {
+	return createdAt.UTC().Format(categoryTimeLayout)
+}
+
+// CategoryStartKey is the key to start a category read at: the next page key if it is within the time bound,
+// or the time bound itself. The key of the next page is exclusive, so isNextPageKey tells to skip it.
+func CategoryStartKey(createdAfter time.Time, nextPageKey string) (string, bool) {
+	timeKey := categoryTimeKey(createdAfter)
+	if nextPageKey < timeKey {
+		return timeKey, false
+	}
+
+	return nextPageKey, true
+}
+
+// ParseCategoryKey reads the event key from the next page key of a category read.
+func ParseCategoryKey(nextPageKey string) (uuid.UUID, int, error) {
+	parts := strings.Split(nextPageKey, "#")
+	if len(parts) != 3 {
+		return uuid.UUID{}, 0, invalidCategoryKey(fmt.Errorf("unexpected number of parts: %d", len(parts)))
+	}
+
+	_, err := time.Parse(categoryTimeLayout, parts[0])
+	if err != nil {
+		return uuid.UUID{}, 0, invalidCategoryKey(err)
+	}
+
+	revision, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return uuid.UUID{}, 0, invalidCategoryKey(err)
+	}
+
+	streamId, err := uuid.Parse(parts[2])
+	if err != nil {
+		return uuid.UUID{}, 0, invalidCategoryKey(err)
+	}
+
+	return streamId, revision, nil
+}
+
+func invalidCategoryKey(err error) error {
+	validationErrors := eserror.NewSimpleValidationError(estypes.NextPageKeyParam, "invalid next page key")
+	return eserror.NewValidationError(err, validationErrors)
+}
@@ -5,54 +5,90 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
 	"time"
 )
 
-func (r *EsRepo) CreateStream(ctx context.Context, streamType string, initialEvent estypes.NewEsEvent) (estypes.Stream, error) {
-	streamId := uuid.New()
+func (r *EsRepo) CreateStream(ctx context.Context, streamType string, initialEvent estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error) {
+	return r.CreateStreamWithId(ctx, streamType, uuid.New(), initialEvent, options)
+}
+
+// CreateStreamWithId fails with eserror.DataConflictError when a stream with the same id already exists.
+func (r *EsRepo) CreateStreamWithId(ctx context.Context, streamType string, streamId uuid.UUID, initialEvent estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error) {
+	return r.CreateStreamWithEvents(ctx, streamType, streamId, []estypes.NewEsEvent{initialEvent}, options)
+}
+
+// CreateStreamWithEvents persists the stream record with its first events in a single transaction,
+// together with the reservations of the options.
+// It fails with eserror.DataConflictError when a stream with the same id already exists.
+func (r *EsRepo) CreateStreamWithEvents(ctx context.Context, streamType string, streamId uuid.UUID, newEvents []estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error) {
+	if len(newEvents) == 0 {
+		return estypes.Stream{}, fmt.Errorf("no events to create stream with")
+	}
+
 	now := time.Now()
 	stream := estypes.NewStream(streamId, streamType, now)
-	event := estypes.NewEvent(streamId, 1, initialEvent, now)
+	stream.Revision = len(newEvents)
 
-	streamPut, err := prepareStreamPut(r.tableName, stream)
+	reservations := options.Reservations
+	streamPut, err := prepareStreamPut(r.tableName, stream, reservations.Claim)
 	if err != nil {
 		return estypes.Stream{}, err
 	}
 
-	eventPut, err := PreparePutEventQuery(r.tableName, event)
+	dbEvents, err := r.prepareDbEvents(ctx, streamType, NewStreamEvents(streamId, 1, newEvents, now), options.EventTtl)
 	if err != nil {
 		return estypes.Stream{}, err
 	}
 
-	_, err = r.dynamoDb.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
-		TransactItems: []types.TransactWriteItem{
-			{
-				Put: streamPut,
-			},
-			{
-				Put: eventPut,
-			},
+	transactItems := []types.TransactWriteItem{
+		{
+			Put: streamPut,
 		},
-		ClientRequestToken: aws.String(streamId.String()), // todo: use better idempotency token; should come from client
-	})
+	}
+
+	if idempotency := options.Idempotency; idempotency != nil {
+		idempotencyPut, err := prepareIdempotencyPut(r.tableName, idempotency.Key, NewIdempotentResult(*idempotency, stream, now), now)
+		if err != nil {
+			return estypes.Stream{}, err
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Put: idempotencyPut})
+	}
+
+	reservationOffset := len(transactItems)
+	reservationItems, err := prepareReservationItems(r.tableName, reservations, stream, now)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+	transactItems = append(transactItems, reservationItems...)
+
+	if len(transactItems)+len(dbEvents)+1 > maxTransactItems {
+		return estypes.Stream{}, fmt.Errorf("too many events to create stream in one transaction: %d", len(newEvents))
+	}
+
+	err = r.transactEvents(ctx, transactItems, dbEvents, now)
 	if err != nil {
-		return estypes.Stream{}, fmt.Errorf("failed to create stream: %w", err)
+		err = fmt.Errorf("failed to create stream: %w", err)
+		return estypes.Stream{}, withReservationConflicts(err, reservationOffset, reservations)
 	}
 
 	return stream, nil
 }
 
-func prepareStreamPut(tableName string, stream estypes.Stream) (*types.Put, error) {
+// prepareStreamPut puts the stream record, which also tracks the claimed keys (see reservationAttr).
+func prepareStreamPut(tableName string, stream estypes.Stream, claimed []estypes.ReservationKey) (*types.Put, error) {
 	dbStream := FromStream(stream)
 
 	value, err := attributevalue.MarshalMap(dbStream)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal db stream: %w", err)
 	}
+	for _, key := range claimed {
+		pk := ReservationPk(key)
+		value[reservationAttr(pk)] = &types.AttributeValueMemberS{Value: pk}
+	}
 
 	put := types.Put{
 		Item:                value,
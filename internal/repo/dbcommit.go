@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"math/rand/v2"
+	"time"
+)
+
+const RecordTypeCommit = "commit"
+
+// allLogPk is the partition of the global log. It is not a stream id, so it never clashes with stream items.
+const allLogPk = "$all"
+
+const maxCommitAttempts = 10
+
+// commitBackoff is the base of the jittered delay between commit attempts, doubled with every attempt
+// up to maxCommitBackoff.
+const (
+	commitBackoff    = 10 * time.Millisecond
+	maxCommitBackoff = 500 * time.Millisecond
+)
+
+// DbCommit is a record of the global log: the events written by a single transaction.
+//
+// The sort key is the position of the first event, the following events take the next positions.
+// A commit takes the position right after the previous commit under the condition that no record has it yet,
+// so positions go without gaps in the order of the transactions.
+type DbCommit struct {
+	Pk         string        `dynamodbav:"PK"`
+	Sk         int           `dynamodbav:"SK"`
+	RecordType string        `dynamodbav:"RecordType"`
+	Events     []dbStreamKey `dynamodbav:"Events"`
+	CreatedAt  time.Time     `dynamodbav:"CreatedAt"`
+}
+
+// nextPosition reads the latest commit to find the position of the next event.
+func (r *EsRepo) nextPosition(ctx context.Context) (int, error) {
+	keyCond, err := expression.NewBuilder().
+		WithKeyCondition(expression.Key("PK").Equal(expression.Value(allLogPk))).
+		Build()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build key condition: %w", err)
+	}
+
+	output, err := r.dynamoDb.Query(ctx, &dynamodb.QueryInput{
+		KeyConditionExpression:    keyCond.KeyCondition(),
+		ExpressionAttributeNames:  keyCond.Names(),
+		ExpressionAttributeValues: keyCond.Values(),
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int32(1),
+		ConsistentRead:            aws.Bool(true),
+		TableName:                 aws.String(r.tableName),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest commit from DB: %w", err)
+	}
+
+	if len(output.Items) == 0 {
+		return 1, nil
+	}
+
+	var latest DbCommit
+	err = attributevalue.UnmarshalMap(output.Items[0], &latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal commit from DB: %w", err)
+	}
+
+	return latest.Sk + len(latest.Events), nil
+}
+
+func prepareCommitPut(tableName string, position int, dbEvents []DbEvent, now time.Time) (*types.Put, error) {
+	commit := DbCommit{
+		Pk:         allLogPk,
+		Sk:         position,
+		RecordType: RecordTypeCommit,
+		Events:     make([]dbStreamKey, 0, len(dbEvents)),
+		CreatedAt:  now.UTC(),
+	}
+	for _, dbEvent := range dbEvents {
+		commit.Events = append(commit.Events, dbStreamKey{Pk: dbEvent.Pk, Sk: dbEvent.Sk})
+	}
+
+	value, err := attributevalue.MarshalMap(commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal commit: %w", err)
+	}
+
+	put := types.Put{
+		Item:                value,
+		TableName:           aws.String(tableName),
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	}
+
+	return &put, nil
+}
+
+// transactEvents writes the events with the other items in a single transaction and records them in the global log.
+// When a concurrent transaction takes the position first or conflicts with this one, the transaction is retried
+// with the next position after a jittered backoff. Once the attempts run out, it fails with eserror.UnavailableError,
+// so the client retries the write later instead of refetching the stream.
+func (r *EsRepo) transactEvents(ctx context.Context, items []types.TransactWriteItem, dbEvents []DbEvent, now time.Time) error {
+	for attempt := 1; ; attempt++ {
+		position, err := r.nextPosition(ctx)
+		if err != nil {
+			return err
+		}
+
+		transactItems := make([]types.TransactWriteItem, 0, len(items)+len(dbEvents)+1)
+		transactItems = append(transactItems, items...)
+		for i := range dbEvents {
+			dbEvents[i].Position = position + i
+
+			eventPut, err := PreparePutEventQuery(r.tableName, dbEvents[i])
+			if err != nil {
+				return err
+			}
+			transactItems = append(transactItems, types.TransactWriteItem{Put: eventPut})
+		}
+
+		commitPut, err := prepareCommitPut(r.tableName, position, dbEvents, now)
+		if err != nil {
+			return err
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Put: commitPut})
+
+		_, err = r.dynamoDb.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems:      transactItems,
+			ClientRequestToken: aws.String(uuid.NewString()),
+		})
+		if err == nil {
+			return nil
+		}
+		if !isCommitContention(err) {
+			return fromTransactionError(err)
+		}
+		if attempt == maxCommitAttempts {
+			return eserror.NewUnavailableError(fmt.Errorf("no position after %d attempts: %w", attempt, err))
+		}
+
+		err = waitBeforeCommit(ctx, attempt)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// waitBeforeCommit sleeps for a random delay up to the exponential backoff of the attempt,
+// so that concurrent writers contending for the next position spread out.
+func waitBeforeCommit(ctx context.Context, attempt int) error {
+	delay := time.Duration(rand.Int64N(int64(min(commitBackoff<<attempt, maxCommitBackoff))))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isCommitContention tells whether the transaction failed only because of concurrent writers:
+// the commit record, the last item of the transaction, failed its condition as the position is taken,
+// or an item was being written by another transaction. A failed condition of any other item is a data conflict.
+func isCommitContention(err error) bool {
+	canceled := &types.TransactionCanceledException{}
+	if !errors.As(err, &canceled) || len(canceled.CancellationReasons) == 0 {
+		return false
+	}
+
+	reasons := canceled.CancellationReasons
+	contention := false
+	for _, reason := range reasons[:len(reasons)-1] {
+		switch aws.ToString(reason.Code) {
+		case conditionalCheckFailed:
+			return false
+		case transactionConflict:
+			contention = true
+		}
+	}
+
+	switch aws.ToString(reasons[len(reasons)-1].Code) {
+	case conditionalCheckFailed, transactionConflict:
+		return true
+	}
+
+	return contention
+}
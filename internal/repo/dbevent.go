@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -12,25 +13,85 @@ import (
 
 const RecordTypeEvent = "event"
 
+// DbEvent is an event record.
+// It has StreamType and CategoryKey attributes for CategoryIndex, but no UpdatedAt, so it does not get into StreamIndexV2.
 type DbEvent struct {
-	Pk         string    `dynamodbav:"PK"`
-	Sk         int       `dynamodbav:"SK"`
-	RecordType string    `dynamodbav:"RecordType"`
-	EventType  string    `dynamodbav:"EventType"`
-	Payload    string    `dynamodbav:"Payload"`
-	CreatedAt  time.Time `dynamodbav:"CreatedAt"`
+	Pk                string            `dynamodbav:"PK"`
+	Sk                int               `dynamodbav:"SK"`
+	RecordType        string            `dynamodbav:"RecordType"`
+	StreamType        string            `dynamodbav:"StreamType,omitempty"`
+	CategoryKey       string            `dynamodbav:"CategoryKey,omitempty"`
+	EventType         string            `dynamodbav:"EventType"`
+	Payload           DbPayload         `dynamodbav:"Payload"`
+	ContentType       string            `dynamodbav:"ContentType,omitempty"`
+	SchemaVersion     int               `dynamodbav:"SchemaVersion,omitempty"`
+	PayloadRef        string            `dynamodbav:"PayloadRef,omitempty"`
+	PayloadChecksum   string            `dynamodbav:"PayloadChecksum,omitempty"`
+	PayloadCodec      string            `dynamodbav:"PayloadCodec,omitempty"`
+	CompressedPayload []byte            `dynamodbav:"CompressedPayload,omitempty"`
+	PayloadCipher     string            `dynamodbav:"PayloadCipher,omitempty"`
+	PayloadKeyId      string            `dynamodbav:"PayloadKeyId,omitempty"`
+	EncryptedPayload  []byte            `dynamodbav:"EncryptedPayload,omitempty"`
+	Shredded          bool              `dynamodbav:"-" json:"-"`
+	CorrelationId     string            `dynamodbav:"CorrelationId,omitempty"`
+	CausationId       string            `dynamodbav:"CausationId,omitempty"`
+	Actor             string            `dynamodbav:"Actor,omitempty"`
+	Headers           map[string]string `dynamodbav:"Headers,omitempty"`
+	CreatedAt         time.Time         `dynamodbav:"CreatedAt"`
+	Position          int               `dynamodbav:"Position,omitempty"`
+	ExpiresAt         int64             `dynamodbav:"ExpiresAt,omitempty" json:"-"`
 }
 
 func FromEvent(event estypes.Event) DbEvent {
 	createdAtUtc := event.CreatedAt.UTC()
 
 	return DbEvent{
-		Pk:         event.StreamId.String(),
-		Sk:         event.Revision,
-		RecordType: RecordTypeEvent,
-		EventType:  event.EventType,
-		Payload:    event.Payload,
-		CreatedAt:  createdAtUtc,
+		Pk:            event.StreamId.String(),
+		Sk:            event.Revision,
+		RecordType:    RecordTypeEvent,
+		EventType:     event.EventType,
+		Payload:       NewDbPayload(event.Payload, event.ContentType),
+		ContentType:   event.ContentType,
+		SchemaVersion: event.SchemaVersion,
+		CorrelationId: event.Metadata.CorrelationId,
+		CausationId:   event.Metadata.CausationId,
+		Actor:         event.Metadata.Actor,
+		Headers:       event.Metadata.Headers,
+		CreatedAt:     createdAtUtc,
+		Position:      event.Position,
+	}
+}
+
+// FromCategoryEvent builds the event record indexed in CategoryIndex under the stream type.
+func FromCategoryEvent(streamType string, event estypes.Event) DbEvent {
+	dbEvent := FromEvent(event)
+	dbEvent.StreamType = streamType
+	dbEvent.CategoryKey = CategoryKey(event.CreatedAt, event.Revision, event.StreamId)
+
+	return dbEvent
+}
+
+// storedPayload returns the payload in the form it is kept in the record: encrypted, compressed or as is.
+func (e *DbEvent) storedPayload() []byte {
+	switch {
+	case e.PayloadCipher != "":
+		return e.EncryptedPayload
+	case e.PayloadCodec != "":
+		return e.CompressedPayload
+	default:
+		return []byte(e.Payload.Text)
+	}
+}
+
+// setStoredPayload is the reverse of storedPayload.
+func (e *DbEvent) setStoredPayload(data []byte) {
+	switch {
+	case e.PayloadCipher != "":
+		e.EncryptedPayload = data
+	case e.PayloadCodec != "":
+		e.CompressedPayload = data
+	default:
+		e.Payload = DbPayload{Text: string(data)}
 	}
 }
 
@@ -40,20 +101,38 @@ func IntoEvent(dbEvent DbEvent) (estypes.Event, error) {
 		return estypes.Event{}, fmt.Errorf("failed to parse streamId: %w", err)
 	}
 
+	payload := dbEvent.Payload.Text
+	if dbEvent.Shredded {
+		payload = ""
+	} else if dbEvent.PayloadCodec != "" {
+		payload, err = decompressPayload(dbEvent)
+		if err != nil {
+			return estypes.Event{}, err
+		}
+	}
+
 	event := estypes.Event{
-		StreamId:  streamId,
-		Revision:  dbEvent.Sk,
-		EventType: dbEvent.EventType,
-		Payload:   dbEvent.Payload,
+		StreamId:      streamId,
+		Revision:      dbEvent.Sk,
+		EventType:     dbEvent.EventType,
+		Payload:       payload,
+		ContentType:   dbEvent.ContentType,
+		SchemaVersion: dbEvent.SchemaVersion,
+		Metadata: estypes.EventMetadata{
+			CorrelationId: dbEvent.CorrelationId,
+			CausationId:   dbEvent.CausationId,
+			Actor:         dbEvent.Actor,
+			Headers:       dbEvent.Headers,
+		},
+		Shredded:  dbEvent.Shredded,
 		CreatedAt: dbEvent.CreatedAt,
+		Position:  dbEvent.Position,
 	}
 
 	return event, nil
 }
 
-func PreparePutEventQuery(tableName string, event estypes.Event) (*types.Put, error) {
-	dbEvent := FromEvent(event)
-
+func PreparePutEventQuery(tableName string, dbEvent DbEvent) (*types.Put, error) {
 	value, err := attributevalue.MarshalMap(dbEvent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal db event: %w", err)
@@ -67,3 +146,34 @@ func PreparePutEventQuery(tableName string, event estypes.Event) (*types.Put, er
 
 	return &put, nil
 }
+
+// prepareDbEvents builds the event records of a write, offloading or compressing the payloads.
+// The records get the TTL of the stream when it has one (see WriteOptions).
+// Their positions are assigned when the transaction is written (see transactEvents).
+func (r *EsRepo) prepareDbEvents(ctx context.Context, streamType string, events []estypes.Event, eventTtl time.Duration) ([]DbEvent, error) {
+	dbEvents := make([]DbEvent, 0, len(events))
+	for _, event := range events {
+		dbEvent := FromCategoryEvent(streamType, event)
+		if eventTtl > 0 {
+			dbEvent.ExpiresAt = event.CreatedAt.Add(eventTtl).Unix()
+		}
+		dbEvents = append(dbEvents, dbEvent)
+	}
+
+	err := r.payloads.Store(ctx, dbEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbEvents, nil
+}
+
+// NewStreamEvents makes the events of a write with consecutive revisions starting from firstRevision.
+func NewStreamEvents(streamId uuid.UUID, firstRevision int, newEvents []estypes.NewEsEvent, now time.Time) []estypes.Event {
+	events := make([]estypes.Event, 0, len(newEvents))
+	for i, newEvent := range newEvents {
+		events = append(events, estypes.NewEvent(streamId, firstRevision+i, newEvent, now))
+	}
+
+	return events
+}
@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"time"
+)
+
+const RecordTypeIdempotency = "idempotency"
+const idempotencyPkPrefix = "idempotency#"
+
+// DbIdempotency keeps the result of a write: Result is the JSON of the stream of a single-stream write,
+// StreamsResult is the JSON of the streams of a multi-stream write.
+// It has no StreamType and UpdatedAt attributes, so it does not get into StreamIndexV2.
+// ExpiresAt is in epoch seconds to be used as DynamoDB TTL attribute.
+type DbIdempotency struct {
+	Pk            string `dynamodbav:"PK"`
+	Sk            int    `dynamodbav:"SK"`
+	RecordType    string `dynamodbav:"RecordType"`
+	RequestHash   string `dynamodbav:"RequestHash"`
+	Result        string `dynamodbav:"Result,omitempty"`
+	StreamsResult string `dynamodbav:"StreamsResult,omitempty"`
+	ExpiresAt     int64  `dynamodbav:"ExpiresAt"`
+}
+
+func idempotencyPk(key string) string {
+	return idempotencyPkPrefix + key
+}
+
+func FromIdempotentResult(key string, result IdempotentResult) (DbIdempotency, error) {
+	dbIdempotency := DbIdempotency{
+		Pk:          idempotencyPk(key),
+		Sk:          0,
+		RecordType:  RecordTypeIdempotency,
+		RequestHash: result.RequestHash,
+		ExpiresAt:   result.ExpiresAt.Unix(),
+	}
+
+	if result.Streams != nil {
+		streamsJson, err := json.Marshal(result.Streams)
+		if err != nil {
+			return DbIdempotency{}, fmt.Errorf("failed to marshal idempotent result: %w", err)
+		}
+		dbIdempotency.StreamsResult = string(streamsJson)
+
+		return dbIdempotency, nil
+	}
+
+	resultJson, err := json.Marshal(result.Stream)
+	if err != nil {
+		return DbIdempotency{}, fmt.Errorf("failed to marshal idempotent result: %w", err)
+	}
+	dbIdempotency.Result = string(resultJson)
+
+	return dbIdempotency, nil
+}
+
+func IntoIdempotentResult(dbIdempotency DbIdempotency) (IdempotentResult, error) {
+	result := IdempotentResult{
+		RequestHash: dbIdempotency.RequestHash,
+		ExpiresAt:   time.Unix(dbIdempotency.ExpiresAt, 0),
+	}
+
+	if dbIdempotency.StreamsResult != "" {
+		err := json.Unmarshal([]byte(dbIdempotency.StreamsResult), &result.Streams)
+		if err != nil {
+			return IdempotentResult{}, fmt.Errorf("failed to unmarshal idempotent result: %w", err)
+		}
+
+		return result, nil
+	}
+
+	err := json.Unmarshal([]byte(dbIdempotency.Result), &result.Stream)
+	if err != nil {
+		return IdempotentResult{}, fmt.Errorf("failed to unmarshal idempotent result: %w", err)
+	}
+
+	return result, nil
+}
+
+// prepareIdempotencyPut allows to overwrite only an expired record,
+// since DynamoDB TTL removes items with a delay.
+func prepareIdempotencyPut(tableName string, key string, result IdempotentResult, now time.Time) (*types.Put, error) {
+	dbIdempotency, err := FromIdempotentResult(key, result)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := attributevalue.MarshalMap(dbIdempotency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal db idempotency: %w", err)
+	}
+
+	condExpr, err := expression.NewBuilder().WithCondition(
+		expression.AttributeNotExists(expression.Name("PK")).
+			Or(expression.Name("ExpiresAt").LessThanEqual(expression.Value(now.Unix()))),
+	).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build idempotency condition: %w", err)
+	}
+
+	put := types.Put{
+		Item:                      value,
+		TableName:                 aws.String(tableName),
+		ConditionExpression:       condExpr.Condition(),
+		ExpressionAttributeNames:  condExpr.Names(),
+		ExpressionAttributeValues: condExpr.Values(),
+	}
+
+	return &put, nil
+}
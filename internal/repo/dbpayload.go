@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"strings"
+)
+
+// DbPayload is an event payload as it is stored.
+//
+// JSON objects, arrays, numbers, booleans and null are stored as native values (a DynamoDB map, list and so on).
+// Everything else, including JSON strings, is stored as a string with the serialized payload,
+// so Text is restored exactly, and ContentType of the event tells how to interpret it.
+type DbPayload struct {
+	Text   string
+	Native bool
+}
+
+func NewDbPayload(payload string, contentType string) DbPayload {
+	trimmed := strings.TrimLeft(payload, " \t\r\n")
+	native := contentType == estypes.ContentTypeJson && len(trimmed) > 0 && trimmed[0] != '"'
+
+	return DbPayload{Text: payload, Native: native}
+}
+
+func (p DbPayload) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	if !p.Native {
+		return &types.AttributeValueMemberS{Value: p.Text}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewBufferString(p.Text))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON payload: %w", err)
+	}
+
+	return attributevalue.Marshal(value)
+}
+
+func (p *DbPayload) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	if s, ok := av.(*types.AttributeValueMemberS); ok {
+		*p = DbPayload{Text: s.Value}
+		return nil
+	}
+
+	value, err := attributeValueToJson(av)
+	if err != nil {
+		return err
+	}
+
+	*p = DbPayload{Text: string(value), Native: true}
+
+	return nil
+}
+
+// MarshalJSON keeps the same layout in JSON-encoded records: native payloads as JSON values, others as strings.
+func (p DbPayload) MarshalJSON() ([]byte, error) {
+	if p.Native {
+		return []byte(p.Text), nil
+	}
+
+	return json.Marshal(p.Text)
+}
+
+func (p *DbPayload) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*p = DbPayload{}
+		return json.Unmarshal(data, &p.Text)
+	}
+
+	*p = DbPayload{Text: string(data), Native: true}
+
+	return nil
+}
+
+func attributeValueToJson(av types.AttributeValue) (json.RawMessage, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return json.Marshal(v.Value)
+	case *types.AttributeValueMemberN:
+		return json.RawMessage(v.Value), nil
+	case *types.AttributeValueMemberBOOL:
+		return json.Marshal(v.Value)
+	case *types.AttributeValueMemberNULL:
+		return json.RawMessage("null"), nil
+	case *types.AttributeValueMemberL:
+		list := make([]json.RawMessage, 0, len(v.Value))
+		for _, item := range v.Value {
+			value, err := attributeValueToJson(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return json.Marshal(list)
+	case *types.AttributeValueMemberM:
+		object := make(map[string]json.RawMessage, len(v.Value))
+		for key, item := range v.Value {
+			value, err := attributeValueToJson(item)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+		}
+		return json.Marshal(object)
+	default:
+		return nil, fmt.Errorf("unsupported payload attribute type %T", av)
+	}
+}
@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const RecordTypeReservation = "reservation"
+const reservationPkPrefix = "reservation#"
+
+// DbReservation is a unique key held by a stream.
+// The holder is kept in OwnerStreamType and OwnerStreamId, so the record gets neither into StreamIndexV2 nor into CategoryIndex.
+// The namespace is escaped in the partition key, so that it cannot run into the key.
+type DbReservation struct {
+	Pk              string    `dynamodbav:"PK"`
+	Sk              int       `dynamodbav:"SK"`
+	RecordType      string    `dynamodbav:"RecordType"`
+	Namespace       string    `dynamodbav:"Namespace"`
+	Key             string    `dynamodbav:"Key"`
+	OwnerStreamType string    `dynamodbav:"OwnerStreamType"`
+	OwnerStreamId   string    `dynamodbav:"OwnerStreamId"`
+	CreatedAt       time.Time `dynamodbav:"CreatedAt"`
+}
+
+func ReservationPk(key estypes.ReservationKey) string {
+	return reservationPkPrefix + url.PathEscape(key.Namespace) + "#" + key.Key
+}
+
+const reservationAttrPrefix = "Reservation_"
+
+// reservationAttr names the attribute of the stream record that tracks a key held by the stream, its value is
+// the partition key of the reservation item. Every key gets an attribute of its own rather than an entry of a set,
+// since an update cannot both add to and delete from the same attribute, while a write may claim and release keys.
+// The name is a hash of the partition key, so that any key makes a valid top-level attribute name.
+func reservationAttr(pk string) string {
+	sum := sha256.Sum256([]byte(pk))
+	return reservationAttrPrefix + hex.EncodeToString(sum[:])
+}
+
+// heldReservations returns the partition keys of the reservation items tracked on the stream record.
+func heldReservations(item map[string]types.AttributeValue) []string {
+	var pks []string
+	for name, value := range item {
+		pk, ok := value.(*types.AttributeValueMemberS)
+		if ok && strings.HasPrefix(name, reservationAttrPrefix) {
+			pks = append(pks, pk.Value)
+		}
+	}
+
+	return pks
+}
+
+func FromReservation(reservation estypes.Reservation) DbReservation {
+	return DbReservation{
+		Pk:              ReservationPk(reservation.ReservationKey()),
+		Sk:              0,
+		RecordType:      RecordTypeReservation,
+		Namespace:       reservation.Namespace,
+		Key:             reservation.Key,
+		OwnerStreamType: reservation.StreamType,
+		OwnerStreamId:   reservation.StreamId.String(),
+		CreatedAt:       reservation.CreatedAt.UTC(),
+	}
+}
+
+func IntoReservation(dbReservation DbReservation) (estypes.Reservation, error) {
+	streamId, err := uuid.Parse(dbReservation.OwnerStreamId)
+	if err != nil {
+		return estypes.Reservation{}, fmt.Errorf("failed to parse owner stream id: %w", err)
+	}
+
+	reservation := estypes.Reservation{
+		Namespace:  dbReservation.Namespace,
+		Key:        dbReservation.Key,
+		StreamType: dbReservation.OwnerStreamType,
+		StreamId:   streamId,
+		CreatedAt:  dbReservation.CreatedAt,
+	}
+
+	return reservation, nil
+}
+
+// prepareReservationItems puts the claimed keys, unless another stream holds them,
+// and deletes the released keys, if the stream holds them. Claims go before releases.
+func prepareReservationItems(tableName string, reservations Reservations, stream estypes.Stream, now time.Time) ([]types.TransactWriteItem, error) {
+	ownedByStream := expression.Name("OwnerStreamId").Equal(expression.Value(stream.StreamId.String()))
+	items := make([]types.TransactWriteItem, 0, reservations.count())
+
+	claimCond, err := expression.NewBuilder().WithCondition(
+		expression.AttributeNotExists(expression.Name("PK")).Or(ownedByStream),
+	).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build reservation condition: %w", err)
+	}
+
+	for _, key := range reservations.Claim {
+		value, err := attributevalue.MarshalMap(FromReservation(estypes.NewReservation(key, stream, now)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal db reservation: %w", err)
+		}
+
+		put := types.Put{
+			Item:                      value,
+			TableName:                 aws.String(tableName),
+			ConditionExpression:       claimCond.Condition(),
+			ExpressionAttributeNames:  claimCond.Names(),
+			ExpressionAttributeValues: claimCond.Values(),
+		}
+		items = append(items, types.TransactWriteItem{Put: &put})
+	}
+
+	releaseCond, err := expression.NewBuilder().WithCondition(ownedByStream).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build reservation condition: %w", err)
+	}
+
+	for _, key := range reservations.Release {
+		keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: ReservationPk(key), Sk: 0})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal reservation key: %w", err)
+		}
+
+		del := types.Delete{
+			Key:                       keyValue,
+			TableName:                 aws.String(tableName),
+			ConditionExpression:       releaseCond.Condition(),
+			ExpressionAttributeNames:  releaseCond.Names(),
+			ExpressionAttributeValues: releaseCond.Values(),
+		}
+		items = append(items, types.TransactWriteItem{Delete: &del})
+	}
+
+	return items, nil
+}
+
+// withReservationConflicts names the keys whose items failed their conditions,
+// the reservation items start at the offset of the transaction.
+func withReservationConflicts(err error, offset int, reservations Reservations) error {
+	canceled := &types.TransactionCanceledException{}
+	if !errors.As(err, &canceled) {
+		return err
+	}
+
+	keys := append(append([]estypes.ReservationKey{}, reservations.Claim...), reservations.Release...)
+	var conflicts []eserror.ReservationConflict
+	for i, key := range keys {
+		if offset+i >= len(canceled.CancellationReasons) {
+			break
+		}
+		if aws.ToString(canceled.CancellationReasons[offset+i].Code) == conditionalCheckFailed {
+			conflicts = append(conflicts, eserror.ReservationConflict{Namespace: key.Namespace, Key: key.Key})
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return err
+	}
+
+	return eserror.NewReservationConflictError(err, conflicts...)
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"time"
+)
+
+const RecordTypeSnapshot = "snapshot"
+
+// snapshotSk keeps the latest snapshot before the stream record and events of the same stream.
+const snapshotSk = -1
+
+// DbSnapshot is the latest snapshot of a stream.
+// It has no StreamType and UpdatedAt attributes, so it does not get into StreamIndexV2.
+type DbSnapshot struct {
+	Pk               string    `dynamodbav:"PK"`
+	Sk               int       `dynamodbav:"SK"`
+	RecordType       string    `dynamodbav:"RecordType"`
+	SnapshotRevision int       `dynamodbav:"SnapshotRevision"`
+	SchemaVersion    int       `dynamodbav:"SchemaVersion"`
+	State            string    `dynamodbav:"State"`
+	CreatedAt        time.Time `dynamodbav:"CreatedAt"`
+}
+
+func FromSnapshot(snapshot estypes.Snapshot) DbSnapshot {
+	createdAtUtc := snapshot.CreatedAt.UTC()
+
+	return DbSnapshot{
+		Pk:               snapshot.StreamId.String(),
+		Sk:               snapshotSk,
+		RecordType:       RecordTypeSnapshot,
+		SnapshotRevision: snapshot.Revision,
+		SchemaVersion:    snapshot.SchemaVersion,
+		State:            snapshot.State,
+		CreatedAt:        createdAtUtc,
+	}
+}
+
+func IntoSnapshot(dbSnapshot DbSnapshot) (estypes.Snapshot, error) {
+	streamId, err := uuid.Parse(dbSnapshot.Pk)
+	if err != nil {
+		return estypes.Snapshot{}, fmt.Errorf("failed to parse streamId: %w", err)
+	}
+
+	snapshot := estypes.Snapshot{
+		StreamId:      streamId,
+		Revision:      dbSnapshot.SnapshotRevision,
+		SchemaVersion: dbSnapshot.SchemaVersion,
+		State:         dbSnapshot.State,
+		CreatedAt:     dbSnapshot.CreatedAt,
+	}
+
+	return snapshot, nil
+}
@@ -8,15 +8,26 @@ import (
 )
 
 const RecordTypeStream = "stream"
-const streamIndexName = "StreamIndex"
+const streamIndexName = "StreamIndexV2"
 
 type DbStream struct {
-	Pk             string    `dynamodbav:"PK"`
-	Sk             int       `dynamodbav:"SK"`
-	RecordType     string    `dynamodbav:"RecordType"`
-	StreamType     string    `dynamodbav:"StreamType"`
-	StreamRevision int       `dynamodbav:"StreamRevision"`
-	UpdatedAt      time.Time `dynamodbav:"UpdatedAt"`
+	Pk              string            `dynamodbav:"PK"`
+	Sk              int               `dynamodbav:"SK"`
+	RecordType      string            `dynamodbav:"RecordType"`
+	StreamType      string            `dynamodbav:"StreamType"`
+	StreamRevision  int               `dynamodbav:"StreamRevision"`
+	UpdatedAt       time.Time         `dynamodbav:"UpdatedAt"`
+	Deleted         string            `dynamodbav:"Deleted,omitempty"`
+	TruncatedBefore int               `dynamodbav:"TruncatedBefore,omitempty"`
+	Metadata        *DbStreamMetadata `dynamodbav:"Metadata,omitempty"`
+}
+
+type DbStreamMetadata struct {
+	MaxAge       int               `dynamodbav:"MaxAge,omitempty"`
+	MaxCount     int               `dynamodbav:"MaxCount,omitempty"`
+	ExpireEvents bool              `dynamodbav:"ExpireEvents,omitempty"`
+	Labels       map[string]string `dynamodbav:"Labels,omitempty"`
+	Owner        string            `dynamodbav:"Owner,omitempty"`
 }
 
 type dbStreamKey struct {
@@ -28,13 +39,26 @@ func FromStream(stream estypes.Stream) DbStream {
 	updatedAtUtc := stream.UpdatedAt.UTC()
 
 	return DbStream{
-		Pk:             stream.StreamId.String(),
-		Sk:             0,
-		RecordType:     RecordTypeStream,
-		StreamType:     stream.StreamType,
-		StreamRevision: stream.Revision,
-		UpdatedAt:      updatedAtUtc,
+		Pk:              stream.StreamId.String(),
+		Sk:              0,
+		RecordType:      RecordTypeStream,
+		StreamType:      stream.StreamType,
+		StreamRevision:  stream.Revision,
+		UpdatedAt:       updatedAtUtc,
+		Deleted:         stream.Deleted,
+		TruncatedBefore: stream.TruncatedBefore,
+		Metadata:        fromStreamMetadata(stream.Metadata),
+	}
+}
+
+func fromStreamMetadata(metadata *estypes.StreamMetadata) *DbStreamMetadata {
+	if metadata == nil {
+		return nil
 	}
+
+	dbMetadata := DbStreamMetadata(*metadata)
+
+	return &dbMetadata
 }
 
 func IntoStream(dbStream DbStream) (estypes.Stream, error) {
@@ -44,10 +68,16 @@ func IntoStream(dbStream DbStream) (estypes.Stream, error) {
 	}
 
 	stream := estypes.Stream{
-		StreamId:   streamId,
-		StreamType: dbStream.StreamType,
-		Revision:   dbStream.StreamRevision,
-		UpdatedAt:  dbStream.UpdatedAt,
+		StreamId:        streamId,
+		StreamType:      dbStream.StreamType,
+		Revision:        dbStream.StreamRevision,
+		UpdatedAt:       dbStream.UpdatedAt,
+		Deleted:         dbStream.Deleted,
+		TruncatedBefore: dbStream.TruncatedBefore,
+	}
+	if dbStream.Metadata != nil {
+		metadata := estypes.StreamMetadata(*dbStream.Metadata)
+		stream.Metadata = &metadata
 	}
 
 	return stream, nil
@@ -0,0 +1,249 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"time"
+)
+
+// maxBatchWriteItems is the limit of items in a single DynamoDB batch write.
+const maxBatchWriteItems = 25
+
+const maxBatchAttempts = 5
+
+// DeleteStream marks the stream record as deleted, which also notifies subscribers of the stream,
+// and releases the unique keys the stream holds.
+//
+// A hard deletion then removes all other items of the stream (events and snapshot) in batches,
+// together with offloaded payloads and the data key. The stream record stays as a tombstone.
+// A deletion may be repeated to finish the release and the removal after a failure,
+// while a soft deletion of a hard-deleted stream fails with eserror.DataConflictError.
+func (r *EsRepo) DeleteStream(ctx context.Context, streamId uuid.UUID, deletion string) (estypes.Stream, error) {
+	streamUpdate, err := prepareStreamDeletion(r.tableName, streamId, deletion, time.Now())
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	output, err := r.dynamoDb.UpdateItem(ctx, streamUpdate)
+	if err != nil {
+		conditionFailed := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &conditionFailed) {
+			err = fmt.Errorf("stream [%s] is missing or already hard-deleted: %w", streamId, err)
+			return estypes.Stream{}, eserror.NewDataConflictError(err)
+		}
+		return estypes.Stream{}, fmt.Errorf("failed to mark stream as deleted: %w", err)
+	}
+
+	var dbStream DbStream
+	err = attributevalue.UnmarshalMap(output.Attributes, &dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to unmarshal stream from DB: %w", err)
+	}
+
+	stream, err := IntoStream(dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to convert DbStream into Stream [%s]: %w", streamId, err)
+	}
+
+	err = r.releaseReservations(ctx, streamId, heldReservations(output.Attributes))
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	if deletion == estypes.DeletionHard {
+		err = r.purgeStream(ctx, streamId)
+		if err != nil {
+			return estypes.Stream{}, err
+		}
+	}
+
+	return stream, nil
+}
+
+func prepareStreamDeletion(tableName string, streamId uuid.UUID, deletion string, now time.Time) (*dynamodb.UpdateItemInput, error) {
+	condition := expression.AttributeExists(expression.Name("PK"))
+	if deletion == estypes.DeletionSoft {
+		condition = condition.And(expression.Or(
+			expression.AttributeNotExists(expression.Name("Deleted")),
+			expression.Name("Deleted").Equal(expression.Value(estypes.DeletionSoft)),
+		))
+	}
+
+	updateExpr, err := expression.NewBuilder().WithUpdate(
+		expression.
+			Set(expression.Name("Deleted"), expression.Value(deletion)).
+			Set(expression.Name("UpdatedAt"), expression.Value(now.UTC())),
+	).
+		WithCondition(condition).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update expression: %w", err)
+	}
+
+	streamKeyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: streamId.String(), Sk: 0})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stream key: %w", err)
+	}
+
+	update := &dynamodb.UpdateItemInput{
+		Key:                       streamKeyValue,
+		TableName:                 aws.String(tableName),
+		UpdateExpression:          updateExpr.Update(),
+		ExpressionAttributeNames:  updateExpr.Names(),
+		ExpressionAttributeValues: updateExpr.Values(),
+		ConditionExpression:       updateExpr.Condition(),
+		ReturnValues:              types.ReturnValueAllNew,
+	}
+
+	return update, nil
+}
+
+// releaseReservations deletes the reservation items held by the stream.
+// Keys already released by an earlier deletion, or claimed by another stream since then, fail the condition and are skipped.
+func (r *EsRepo) releaseReservations(ctx context.Context, streamId uuid.UUID, pks []string) error {
+	if len(pks) == 0 {
+		return nil
+	}
+
+	releaseCond, err := expression.NewBuilder().WithCondition(
+		expression.Name("OwnerStreamId").Equal(expression.Value(streamId.String())),
+	).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build reservation condition: %w", err)
+	}
+
+	for _, pk := range pks {
+		keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: pk, Sk: 0})
+		if err != nil {
+			return fmt.Errorf("failed to marshal reservation key: %w", err)
+		}
+
+		_, err = r.dynamoDb.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			Key:                       keyValue,
+			TableName:                 aws.String(r.tableName),
+			ConditionExpression:       releaseCond.Condition(),
+			ExpressionAttributeNames:  releaseCond.Names(),
+			ExpressionAttributeValues: releaseCond.Values(),
+		})
+		conditionFailed := &types.ConditionalCheckFailedException{}
+		if err != nil && !errors.As(err, &conditionFailed) {
+			return fmt.Errorf("failed to release reservation of stream [%s]: %w", streamId, err)
+		}
+	}
+
+	return nil
+}
+
+// purgeStream deletes all items of the stream but the stream record.
+func (r *EsRepo) purgeStream(ctx context.Context, streamId uuid.UUID) error {
+	keyCond := expression.Key("PK").Equal(expression.Value(streamId.String()))
+
+	payloadRefs, err := r.deleteStreamItems(ctx, streamId, keyCond)
+	if err != nil {
+		return err
+	}
+
+	return r.payloads.Discard(ctx, streamId, payloadRefs)
+}
+
+// deleteStreamItems deletes the items matching the key condition in batches, skipping the stream record.
+// It returns the keys of offloaded payloads of the deleted events.
+func (r *EsRepo) deleteStreamItems(ctx context.Context, streamId uuid.UUID, keyCond expression.KeyConditionBuilder) ([]string, error) {
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyCond).
+		WithProjection(expression.NamesList(expression.Name("PK"), expression.Name("SK"), expression.Name("PayloadRef"))).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build key condition: %w", err)
+	}
+
+	query := &dynamodb.QueryInput{
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		TableName:                 aws.String(r.tableName),
+	}
+
+	var payloadRefs []string
+	paginator := dynamodb.NewQueryPaginator(r.dynamoDb, query)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get items of stream [%s] from DB: %w", streamId, err)
+		}
+
+		deletes := make([]types.WriteRequest, 0, len(output.Items))
+		for _, item := range output.Items {
+			var dbItem struct {
+				dbStreamKey
+				PayloadRef string `dynamodbav:"PayloadRef"`
+			}
+			err = attributevalue.UnmarshalMap(item, &dbItem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal stream item from DB: %w", err)
+			}
+
+			if dbItem.Sk == 0 {
+				continue
+			}
+			if dbItem.PayloadRef != "" {
+				payloadRefs = append(payloadRefs, dbItem.PayloadRef)
+			}
+
+			keyValue, err := attributevalue.MarshalMap(dbItem.dbStreamKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal stream item key: %w", err)
+			}
+			deletes = append(deletes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: keyValue}})
+		}
+
+		for start := 0; start < len(deletes); start += maxBatchWriteItems {
+			end := min(start+maxBatchWriteItems, len(deletes))
+			err = r.batchWrite(ctx, deletes[start:end])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return payloadRefs, nil
+}
+
+// batchWrite retries unprocessed items with a growing delay.
+func (r *EsRepo) batchWrite(ctx context.Context, writes []types.WriteRequest) error {
+	delay := 50 * time.Millisecond
+
+	for attempt := 1; ; attempt++ {
+		output, err := r.dynamoDb.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{r.tableName: writes},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to batch write items: %w", err)
+		}
+
+		writes = output.UnprocessedItems[r.tableName]
+		if len(writes) == 0 {
+			return nil
+		}
+		if attempt == maxBatchAttempts {
+			return fmt.Errorf("failed to batch write items: %d items left unprocessed", len(writes))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
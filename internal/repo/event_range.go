@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"slices"
+)
+
+// MaxLimit caps the number of items evaluated by a single read, larger limits are clamped to it.
+const MaxLimit = 1000
+
+// EventRange selects the events of a stream to read.
+//
+// Bounds are given in the reading direction: events are read after AfterRevision (exclusive)
+// up to ToRevision (inclusive), so that the next page is read after the last evaluated revision
+// in either direction. Zero bounds are open: forward reads start at the first event and end at the latest one,
+// backward reads start at the latest event and end at the first one.
+// Limit caps the number of evaluated events, zero means the page size of the storage (see MaxLimit).
+//
+// EventTypes, when not empty, keeps only the events of these types in the page. Events of other types
+// are still evaluated, so the last evaluated revision advances past them and paging stays the same.
+type EventRange struct {
+	AfterRevision int
+	ToRevision    int
+	Backward      bool
+	Limit         int
+	EventTypes    []string
+}
+
+// Revisions returns the lowest and the highest revision of the range within the stream,
+// excluding events hidden by MaxCount of the stream metadata and events before the truncation point,
+// which an unfinished truncation may not have deleted yet. The range is empty when lowest > highest.
+func (q EventRange) Revisions(stream estypes.Stream) (int, int) {
+	lowest := max(stream.Metadata.EarliestRevision(stream.Revision), stream.TruncatedBefore)
+	highest := stream.Revision
+
+	if q.Backward {
+		if q.AfterRevision > 0 {
+			highest = min(highest, q.AfterRevision-1)
+		}
+		lowest = max(lowest, q.ToRevision)
+	} else {
+		lowest = max(lowest, q.AfterRevision+1)
+		if q.ToRevision > 0 {
+			highest = min(highest, q.ToRevision)
+		}
+	}
+
+	return lowest, highest
+}
+
+// HasMoreAfter tells whether the range has revisions after the given one in the reading direction.
+func (q EventRange) HasMoreAfter(revision int, lowest int, highest int) bool {
+	if q.Backward {
+		return revision > lowest
+	}
+
+	return revision < highest
+}
+
+// End returns the last revision of the range in the reading direction.
+func (q EventRange) End(lowest int, highest int) int {
+	if q.Backward {
+		return lowest
+	}
+
+	return highest
+}
+
+// Selects tells whether events of the type are kept in the page.
+func (q EventRange) Selects(eventType string) bool {
+	return len(q.EventTypes) == 0 || slices.Contains(q.EventTypes, eventType)
+}
@@ -0,0 +1,289 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"time"
+)
+
+// allPageSize is the default number of positions evaluated for a page of the global log.
+const allPageSize = 100
+
+// maxBatchGetItems is the limit of keys in a single DynamoDB batch get.
+const maxBatchGetItems = 100
+
+// GetAllEvents reads a page of the global log after the position, evaluating at most limit positions.
+//
+// Commit records are read with strong consistency: a commit is only written after the previous position is taken,
+// so once a position is visible, all positions before it are visible as well.
+// Events their streams do not serve anymore (see estypes.Stream.Serves) are skipped.
+func (r *EsRepo) GetAllEvents(ctx context.Context, afterPosition int, limit int) (estypes.AllEventPage, error) {
+	if limit <= 0 {
+		limit = allPageSize
+	}
+	limit = min(limit, MaxLimit)
+
+	startPosition, err := r.commitStart(ctx, afterPosition+1)
+	if err != nil {
+		return estypes.AllEventPage{}, err
+	}
+
+	keyCond, err := expression.NewBuilder().
+		WithKeyCondition(
+			expression.Key("PK").Equal(expression.Value(allLogPk)).
+				And(expression.Key("SK").GreaterThanEqual(expression.Value(startPosition))),
+		).
+		Build()
+	if err != nil {
+		return estypes.AllEventPage{}, fmt.Errorf("failed to build key condition: %w", err)
+	}
+
+	output, err := r.dynamoDb.Query(ctx, &dynamodb.QueryInput{
+		KeyConditionExpression:    keyCond.KeyCondition(),
+		ExpressionAttributeNames:  keyCond.Names(),
+		ExpressionAttributeValues: keyCond.Values(),
+		Limit:                     aws.Int32(int32(limit)),
+		ConsistentRead:            aws.Bool(true),
+		TableName:                 aws.String(r.tableName),
+	})
+	if err != nil {
+		return estypes.AllEventPage{}, fmt.Errorf("failed to get commits from DB: %w", err)
+	}
+
+	lastEvaluatedPosition := afterPosition
+	hasMore := output.LastEvaluatedKey != nil
+	eventKeys := make([]dbStreamKey, 0, limit)
+
+commits:
+	for _, item := range output.Items {
+		var commit DbCommit
+		err = attributevalue.UnmarshalMap(item, &commit)
+		if err != nil {
+			return estypes.AllEventPage{}, fmt.Errorf("failed to unmarshal commit from DB: %w", err)
+		}
+
+		for i, eventKey := range commit.Events {
+			position := commit.Sk + i
+			if position <= afterPosition {
+				continue
+			}
+			if len(eventKeys) == limit {
+				hasMore = true
+				break commits
+			}
+
+			eventKeys = append(eventKeys, eventKey)
+			lastEvaluatedPosition = position
+		}
+	}
+
+	events, err := r.batchGetEvents(ctx, eventKeys)
+	if err != nil {
+		return estypes.AllEventPage{}, err
+	}
+
+	events, err = r.servedEvents(ctx, events)
+	if err != nil {
+		return estypes.AllEventPage{}, err
+	}
+
+	page := estypes.AllEventPage{
+		Events:                events,
+		HasMore:               hasMore,
+		LastEvaluatedPosition: lastEvaluatedPosition,
+	}
+
+	return page, nil
+}
+
+// commitStart returns the position of the commit containing the given position,
+// or the position itself when it has not been taken yet.
+func (r *EsRepo) commitStart(ctx context.Context, position int) (int, error) {
+	keyCond, err := expression.NewBuilder().
+		WithKeyCondition(
+			expression.Key("PK").Equal(expression.Value(allLogPk)).
+				And(expression.Key("SK").LessThanEqual(expression.Value(position))),
+		).
+		Build()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build key condition: %w", err)
+	}
+
+	output, err := r.dynamoDb.Query(ctx, &dynamodb.QueryInput{
+		KeyConditionExpression:    keyCond.KeyCondition(),
+		ExpressionAttributeNames:  keyCond.Names(),
+		ExpressionAttributeValues: keyCond.Values(),
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int32(1),
+		ConsistentRead:            aws.Bool(true),
+		TableName:                 aws.String(r.tableName),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commit from DB: %w", err)
+	}
+
+	if len(output.Items) == 0 {
+		return position, nil
+	}
+
+	var commit DbCommit
+	err = attributevalue.UnmarshalMap(output.Items[0], &commit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal commit from DB: %w", err)
+	}
+
+	if commit.Sk+len(commit.Events) <= position {
+		return position, nil
+	}
+
+	return commit.Sk, nil
+}
+
+// batchGetEvents reads the events in the order of the keys, skipping the ones that are no longer stored.
+func (r *EsRepo) batchGetEvents(ctx context.Context, eventKeys []dbStreamKey) ([]estypes.Event, error) {
+	found := make(map[dbStreamKey]DbEvent, len(eventKeys))
+
+	for start := 0; start < len(eventKeys); start += maxBatchGetItems {
+		end := min(start+maxBatchGetItems, len(eventKeys))
+
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, eventKey := range eventKeys[start:end] {
+			keyValue, err := attributevalue.MarshalMap(eventKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal event key: %w", err)
+			}
+			keys = append(keys, keyValue)
+		}
+
+		items, err := r.batchGet(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			var dbEvent DbEvent
+			err = attributevalue.UnmarshalMap(item, &dbEvent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal event from DB: %w", err)
+			}
+			found[dbStreamKey{Pk: dbEvent.Pk, Sk: dbEvent.Sk}] = dbEvent
+		}
+	}
+
+	events := make([]estypes.Event, 0, len(found))
+	for _, eventKey := range eventKeys {
+		dbEvent, ok := found[eventKey]
+		if !ok {
+			continue
+		}
+
+		err := r.payloads.Load(ctx, &dbEvent)
+		if err != nil {
+			return nil, err
+		}
+
+		event, err := IntoEvent(dbEvent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert DbEvent into Event [%s::%d]: %w", dbEvent.Pk, dbEvent.Sk, err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// servedEvents keeps the events their streams serve, reading the stream records of the page in batches,
+// and sets their StreamType.
+func (r *EsRepo) servedEvents(ctx context.Context, events []estypes.Event) ([]estypes.Event, error) {
+	streams := make(map[uuid.UUID]estypes.Stream)
+	var keys []map[string]types.AttributeValue
+	for _, event := range events {
+		if _, ok := streams[event.StreamId]; ok {
+			continue
+		}
+		streams[event.StreamId] = estypes.Stream{}
+
+		keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: event.StreamId.String(), Sk: 0})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal stream key: %w", err)
+		}
+		keys = append(keys, keyValue)
+	}
+
+	for start := 0; start < len(keys); start += maxBatchGetItems {
+		end := min(start+maxBatchGetItems, len(keys))
+
+		items, err := r.batchGet(ctx, keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			var dbStream DbStream
+			err = attributevalue.UnmarshalMap(item, &dbStream)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal stream from DB: %w", err)
+			}
+
+			stream, err := IntoStream(dbStream)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert DbStream into Stream [%s]: %w", dbStream.Pk, err)
+			}
+			streams[stream.StreamId] = stream
+		}
+	}
+
+	now := time.Now()
+	served := make([]estypes.Event, 0, len(events))
+	for _, event := range events {
+		stream := streams[event.StreamId]
+		if stream.StreamId != uuid.Nil && stream.Serves(event, now) {
+			event.StreamType = stream.StreamType
+			served = append(served, event)
+		}
+	}
+
+	return served, nil
+}
+
+// batchGet retries unprocessed keys with a growing delay, same as batchWrite.
+func (r *EsRepo) batchGet(ctx context.Context, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	delay := 50 * time.Millisecond
+
+	for attempt := 1; ; attempt++ {
+		output, err := r.dynamoDb.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				r.tableName: {Keys: keys, ConsistentRead: aws.Bool(true)},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to batch get items: %w", err)
+		}
+
+		items = append(items, output.Responses[r.tableName]...)
+
+		keys = output.UnprocessedKeys[r.tableName].Keys
+		if len(keys) == 0 {
+			return items, nil
+		}
+		if attempt == maxBatchAttempts {
+			return nil, fmt.Errorf("failed to batch get items: %d keys left unprocessed", len(keys))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"time"
+)
+
+// categoryPageSize is the default number of events in a page of a category read.
+const categoryPageSize = 100
+
+// categorySettleWindow holds a category read back from the latest events. CreatedAt is taken before the write
+// completes, and CategoryIndex is updated with a delay, so an event may show up in the index after later events.
+// Reading only events older than the window keeps a saved next page key from skipping them for good.
+// It covers the retries of a write (see transactEvents) and the usual propagation delay of the index.
+const categorySettleWindow = 10 * time.Second
+
+type categoryIndexKey struct {
+	Pk          string `dynamodbav:"PK"`
+	Sk          int    `dynamodbav:"SK"`
+	StreamType  string `dynamodbav:"StreamType"`
+	CategoryKey string `dynamodbav:"CategoryKey"`
+}
+
+// GetCategoryEvents reads a page of events of the stream type created at createdAfter or later,
+// continuing after nextPageKey when it is given.
+//
+// CategoryIndex only keeps the keys of the events, which are then read from the table.
+// The index is eventually consistent, so events of the last categorySettleWindow are left for later reads.
+// Events their streams do not serve anymore (see estypes.Stream.Serves) are skipped.
+func (r *EsRepo) GetCategoryEvents(ctx context.Context, streamType string, createdAfter time.Time, nextPageKey string, limit int) (estypes.CategoryEventPage, error) {
+	if limit <= 0 {
+		limit = categoryPageSize
+	}
+	limit = min(limit, MaxLimit)
+
+	settledKey := categoryTimeKey(time.Now().Add(-categorySettleWindow))
+	if startKey, _ := CategoryStartKey(createdAfter, nextPageKey); startKey >= settledKey {
+		return settledCategoryPage(nextPageKey), nil
+	}
+
+	categoryQuery, err := prepareCategoryQuery(r.tableName, streamType, createdAfter, settledKey, nextPageKey, limit)
+	if err != nil {
+		return estypes.CategoryEventPage{}, err
+	}
+
+	output, err := r.dynamoDb.Query(ctx, categoryQuery)
+	if err != nil {
+		return estypes.CategoryEventPage{}, fmt.Errorf("failed to get category events from DB: %w", err)
+	}
+
+	eventKeys := make([]dbStreamKey, 0, len(output.Items))
+	for _, item := range output.Items {
+		var indexKey categoryIndexKey
+		err = attributevalue.UnmarshalMap(item, &indexKey)
+		if err != nil {
+			return estypes.CategoryEventPage{}, fmt.Errorf("failed to unmarshal category index key from DB: %w", err)
+		}
+
+		eventKeys = append(eventKeys, dbStreamKey{Pk: indexKey.Pk, Sk: indexKey.Sk})
+		nextPageKey = indexKey.CategoryKey
+	}
+
+	events, err := r.batchGetEvents(ctx, eventKeys)
+	if err != nil {
+		return estypes.CategoryEventPage{}, err
+	}
+
+	events, err = r.servedEvents(ctx, events)
+	if err != nil {
+		return estypes.CategoryEventPage{}, err
+	}
+
+	page := estypes.CategoryEventPage{
+		Events:  events,
+		HasMore: output.LastEvaluatedKey != nil,
+	}
+	if nextPageKey != "" {
+		page.NextPageKey = &nextPageKey
+	}
+
+	return page, nil
+}
+
+// settledCategoryPage is an empty page when all requested events are still within categorySettleWindow.
+// It keeps the next page key of the request, so the read resumes from the same place.
+func settledCategoryPage(nextPageKey string) estypes.CategoryEventPage {
+	page := estypes.CategoryEventPage{
+		Events: []estypes.Event{},
+	}
+	if nextPageKey != "" {
+		page.NextPageKey = &nextPageKey
+	}
+
+	return page
+}
+
+func prepareCategoryQuery(tableName string, streamType string, createdAfter time.Time, settledKey string, nextPageKey string, limit int) (*dynamodb.QueryInput, error) {
+	keyCond, err := expression.NewBuilder().
+		WithKeyCondition(
+			expression.Key("StreamType").Equal(expression.Value(streamType)).
+				And(expression.Key("CategoryKey").Between(expression.Value(categoryTimeKey(createdAfter)), expression.Value(settledKey))),
+		).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build key condition: %w", err)
+	}
+
+	query := &dynamodb.QueryInput{
+		KeyConditionExpression:    keyCond.KeyCondition(),
+		ExpressionAttributeNames:  keyCond.Names(),
+		ExpressionAttributeValues: keyCond.Values(),
+		TableName:                 aws.String(tableName),
+		IndexName:                 aws.String(categoryIndexName),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     aws.Int32(int32(limit)),
+	}
+
+	if nextPageKey == "" {
+		return query, nil
+	}
+
+	streamId, revision, err := ParseCategoryKey(nextPageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// a start key outside the key condition is rejected by DynamoDB
+	if _, isNextPageKey := CategoryStartKey(createdAfter, nextPageKey); isNextPageKey {
+		startKey := categoryIndexKey{
+			Pk:          streamId.String(),
+			Sk:          revision,
+			StreamType:  streamType,
+			CategoryKey: nextPageKey,
+		}
+		query.ExclusiveStartKey, err = attributevalue.MarshalMap(startKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal next page key: %w", err)
+		}
+	}
+
+	return query, nil
+}
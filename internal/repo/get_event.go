@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// GetEvent reads a single event by its revision.
+// It fails with eserror.NotFoundError when there is no such event, e.g. after the stream is truncated.
+func (r *EsRepo) GetEvent(ctx context.Context, streamId uuid.UUID, revision int) (estypes.Event, error) {
+	if revision < 1 {
+		err := fmt.Errorf("event not found [%s::%d]", streamId, revision)
+		return estypes.Event{}, eserror.NewNotFoundError(err)
+	}
+
+	keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: streamId.String(), Sk: revision})
+	if err != nil {
+		return estypes.Event{}, fmt.Errorf("failed to marshal event key: %w", err)
+	}
+
+	output, err := r.dynamoDb.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:       keyValue,
+		TableName: aws.String(r.tableName),
+	})
+	if err != nil {
+		return estypes.Event{}, fmt.Errorf("failed to get event from DB: %w", err)
+	}
+
+	if output.Item == nil {
+		err = fmt.Errorf("event not found [%s::%d]", streamId, revision)
+		return estypes.Event{}, eserror.NewNotFoundError(err)
+	}
+
+	var dbEvent DbEvent
+	err = attributevalue.UnmarshalMap(output.Item, &dbEvent)
+	if err != nil {
+		return estypes.Event{}, fmt.Errorf("failed to unmarshal event from DB: %w", err)
+	}
+
+	err = r.payloads.Load(ctx, &dbEvent)
+	if err != nil {
+		return estypes.Event{}, err
+	}
+
+	event, err := IntoEvent(dbEvent)
+	if err != nil {
+		return estypes.Event{}, fmt.Errorf("failed to convert DbEvent into Event [%s::%d]: %w", streamId, dbEvent.Sk, err)
+	}
+
+	return event, nil
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -9,10 +10,30 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/google/uuid"
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"time"
 )
 
-func (r *EsRepo) GetEvents(ctx context.Context, streamId uuid.UUID, afterRevision int) (estypes.EventPage, error) {
-	eventsQuery, err := prepareEventsQuery(r.tableName, streamId, afterRevision)
+// GetEvents reads a page of events within the range.
+// Events hidden by the stream metadata (see estypes.StreamMetadata) are skipped.
+func (r *EsRepo) GetEvents(ctx context.Context, streamId uuid.UUID, eventRange EventRange) (estypes.EventPage, error) {
+	emptyPage := estypes.EventPage{Events: []estypes.Event{}}
+
+	stream, err := r.GetStream(ctx, streamId)
+	if errors.As(err, new(*eserror.NotFoundError)) {
+		return emptyPage, nil
+	}
+	if err != nil {
+		return estypes.EventPage{}, err
+	}
+
+	lowest, highest := eventRange.Revisions(stream)
+	if lowest > highest {
+		return emptyPage, nil
+	}
+	now := time.Now()
+
+	eventsQuery, err := prepareEventsQuery(r.tableName, streamId, lowest, highest, eventRange)
 	if err != nil {
 		return estypes.EventPage{}, fmt.Errorf("failed to prepare DbEventsQuery: %w", err)
 	}
@@ -23,7 +44,6 @@ func (r *EsRepo) GetEvents(ctx context.Context, streamId uuid.UUID, afterRevisio
 	}
 
 	events := make([]estypes.Event, 0, len(output.Items))
-	var lastEvaluatedRevision int
 
 	for _, item := range output.Items {
 		var dbEvent DbEvent
@@ -32,48 +52,93 @@ func (r *EsRepo) GetEvents(ctx context.Context, streamId uuid.UUID, afterRevisio
 			return estypes.EventPage{}, fmt.Errorf("failed to unmarshal event from DB: %w", err)
 		}
 
+		if stream.Metadata.IsExpired(dbEvent.CreatedAt, now) {
+			continue
+		}
+
+		err = r.payloads.Load(ctx, &dbEvent)
+		if err != nil {
+			return estypes.EventPage{}, err
+		}
+
 		event, err := IntoEvent(dbEvent)
 		if err != nil {
 			return estypes.EventPage{}, fmt.Errorf("failed to convert DbEvent into Event [%s::%d]: %w", streamId, dbEvent.Sk, err)
 		}
 
-		lastEvaluatedRevision = event.Revision
 		events = append(events, event)
 	}
 
+	lastEvaluatedRevision, err := lastEvaluatedEventRevision(output, eventRange, lowest, highest)
+	if err != nil {
+		return estypes.EventPage{}, err
+	}
+
 	page := estypes.EventPage{
 		Events:                events,
-		HasMore:               output.LastEvaluatedKey != nil,
+		HasMore:               output.LastEvaluatedKey != nil && eventRange.HasMoreAfter(lastEvaluatedRevision, lowest, highest),
 		LastEvaluatedRevision: lastEvaluatedRevision,
 	}
 
 	return page, nil
 }
 
-func prepareEventsQuery(tableName string, streamId uuid.UUID, afterRevision int) (*dynamodb.QueryInput, error) {
-	keyCond, err := expression.NewBuilder().
-		WithKeyCondition(expression.Key("PK").Equal(expression.Value(streamId.String()))).
-		Build()
+// lastEvaluatedEventRevision is the revision of LastEvaluatedKey. Events filtered out by type are not returned,
+// so the last returned item may be behind it. Without LastEvaluatedKey the whole range has been evaluated.
+func lastEvaluatedEventRevision(output *dynamodb.QueryOutput, eventRange EventRange, lowest int, highest int) (int, error) {
+	if output.LastEvaluatedKey == nil {
+		if len(eventRange.EventTypes) == 0 && len(output.Items) == 0 {
+			return 0, nil
+		}
+		return eventRange.End(lowest, highest), nil
+	}
+
+	var lastKey dbStreamKey
+	err := attributevalue.UnmarshalMap(output.LastEvaluatedKey, &lastKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build key condition: %w", err)
+		return 0, fmt.Errorf("failed to unmarshal last evaluated key: %w", err)
 	}
 
-	exclusiveStartKeySrc := map[string]any{
-		"PK": streamId.String(),
-		"SK": afterRevision,
+	return lastKey.Sk, nil
+}
+
+// prepareEventsQuery selects event records between the revisions, which keeps the stream record and the snapshot out.
+// Event types are filtered after the key condition, so DynamoDB applies Limit before the filter.
+func prepareEventsQuery(tableName string, streamId uuid.UUID, lowest int, highest int, eventRange EventRange) (*dynamodb.QueryInput, error) {
+	builder := expression.NewBuilder().
+		WithKeyCondition(
+			expression.Key("PK").Equal(expression.Value(streamId.String())).
+				And(expression.Key("SK").Between(expression.Value(lowest), expression.Value(highest))),
+		)
+	if len(eventRange.EventTypes) > 0 {
+		builder = builder.WithFilter(eventTypeFilter(eventRange.EventTypes))
 	}
-	exclusiveStartKey, err := attributevalue.MarshalMap(exclusiveStartKeySrc)
+
+	keyCond, err := builder.Build()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal exclusiveStartKey: %w", err)
+		return nil, fmt.Errorf("failed to build key condition: %w", err)
 	}
 
 	query := &dynamodb.QueryInput{
 		KeyConditionExpression:    keyCond.KeyCondition(),
+		FilterExpression:          keyCond.Filter(),
 		ExpressionAttributeNames:  keyCond.Names(),
 		ExpressionAttributeValues: keyCond.Values(),
-		ExclusiveStartKey:         exclusiveStartKey,
+		ScanIndexForward:          aws.Bool(!eventRange.Backward),
 		TableName:                 aws.String(tableName),
 	}
+	if eventRange.Limit > 0 {
+		query.Limit = aws.Int32(int32(min(eventRange.Limit, MaxLimit)))
+	}
 
 	return query, nil
 }
+
+func eventTypeFilter(eventTypes []string) expression.ConditionBuilder {
+	values := make([]expression.OperandBuilder, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		values = append(values, expression.Value(eventType))
+	}
+
+	return expression.Name("EventType").In(values[0], values[1:]...)
+}
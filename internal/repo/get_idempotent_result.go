@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"time"
+)
+
+func (r *EsRepo) GetIdempotentResult(ctx context.Context, key string) (IdempotentResult, error) {
+	keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: idempotencyPk(key), Sk: 0})
+	if err != nil {
+		return IdempotentResult{}, fmt.Errorf("failed to marshal idempotency key: %w", err)
+	}
+
+	output, err := r.dynamoDb.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:            keyValue,
+		TableName:      aws.String(r.tableName),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return IdempotentResult{}, fmt.Errorf("failed to get idempotent result from DB: %w", err)
+	}
+
+	if output.Item == nil {
+		err = fmt.Errorf("idempotent result not found")
+		return IdempotentResult{}, eserror.NewNotFoundError(err)
+	}
+
+	var dbIdempotency DbIdempotency
+	err = attributevalue.UnmarshalMap(output.Item, &dbIdempotency)
+	if err != nil {
+		return IdempotentResult{}, fmt.Errorf("failed to unmarshal idempotent result from DB: %w", err)
+	}
+
+	result, err := IntoIdempotentResult(dbIdempotency)
+	if err != nil {
+		return IdempotentResult{}, err
+	}
+
+	if result.IsExpired(time.Now()) {
+		err = fmt.Errorf("idempotent result expired")
+		return IdempotentResult{}, eserror.NewNotFoundError(err)
+	}
+
+	return result, nil
+}
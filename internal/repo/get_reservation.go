@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// GetReservation tells which stream holds the key, it fails with eserror.NotFoundError when the key is free.
+func (r *EsRepo) GetReservation(ctx context.Context, key estypes.ReservationKey) (estypes.Reservation, error) {
+	keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: ReservationPk(key), Sk: 0})
+	if err != nil {
+		return estypes.Reservation{}, fmt.Errorf("failed to marshal reservation key: %w", err)
+	}
+
+	output, err := r.dynamoDb.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:            keyValue,
+		TableName:      aws.String(r.tableName),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return estypes.Reservation{}, fmt.Errorf("failed to get reservation from DB: %w", err)
+	}
+
+	if output.Item == nil {
+		err = fmt.Errorf("reservation not found [%s::%s]", key.Namespace, key.Key)
+		return estypes.Reservation{}, eserror.NewNotFoundError(err)
+	}
+
+	var dbReservation DbReservation
+	err = attributevalue.UnmarshalMap(output.Item, &dbReservation)
+	if err != nil {
+		return estypes.Reservation{}, fmt.Errorf("failed to unmarshal reservation from DB: %w", err)
+	}
+
+	return IntoReservation(dbReservation)
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+func (r *EsRepo) GetSnapshot(ctx context.Context, streamId uuid.UUID) (estypes.Snapshot, error) {
+	keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: streamId.String(), Sk: snapshotSk})
+	if err != nil {
+		return estypes.Snapshot{}, fmt.Errorf("failed to marshal snapshot key: %w", err)
+	}
+
+	output, err := r.dynamoDb.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:       keyValue,
+		TableName: aws.String(r.tableName),
+	})
+	if err != nil {
+		return estypes.Snapshot{}, fmt.Errorf("failed to get snapshot from DB: %w", err)
+	}
+
+	if output.Item == nil {
+		err = fmt.Errorf("snapshot not found")
+		return estypes.Snapshot{}, eserror.NewNotFoundError(err)
+	}
+
+	var dbSnapshot DbSnapshot
+	err = attributevalue.UnmarshalMap(output.Item, &dbSnapshot)
+	if err != nil {
+		return estypes.Snapshot{}, fmt.Errorf("failed to unmarshal snapshot from DB: %w", err)
+	}
+
+	snapshot, err := IntoSnapshot(dbSnapshot)
+	if err != nil {
+		return estypes.Snapshot{}, fmt.Errorf("failed to convert DbSnapshot into Snapshot [%s]: %w", streamId, err)
+	}
+
+	return snapshot, nil
+}
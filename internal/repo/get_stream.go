@@ -11,6 +11,8 @@ import (
 	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
 )
 
+// GetStream reads the stream record with strong consistency: writes are checked against its revision,
+// and a stale one would only fail the write or make it retry.
 func (r *EsRepo) GetStream(ctx context.Context, streamId uuid.UUID) (estypes.Stream, error) {
 	streamGet, err := prepareStreamGet(r.tableName, streamId)
 	if err != nil {
@@ -53,8 +55,9 @@ func prepareStreamGet(tableName string, streamId uuid.UUID) (*dynamodb.GetItemIn
 	}
 
 	get := &dynamodb.GetItemInput{
-		Key:       key,
-		TableName: aws.String(tableName),
+		Key:            key,
+		TableName:      aws.String(tableName),
+		ConsistentRead: aws.Bool(true),
 	}
 
 	return get, nil
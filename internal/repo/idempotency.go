@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"time"
+)
+
+// IdempotencyWindow is how long a write can be replayed with the same idempotency key.
+const IdempotencyWindow = 24 * time.Hour
+
+// Idempotency identifies a client request that may be retried.
+//
+// RequestHash is used to tell a replay of the same request from a reuse of the key for a different one.
+type Idempotency struct {
+	Key         string
+	RequestHash string
+}
+
+// IdempotentResult is the outcome of a write remembered under an idempotency key:
+// the Stream of a single-stream write, or the Streams of a multi-stream write (see AppendToStreams).
+type IdempotentResult struct {
+	RequestHash string
+	Stream      estypes.Stream
+	Streams     []estypes.Stream
+	ExpiresAt   time.Time
+}
+
+func NewIdempotentResult(idempotency Idempotency, stream estypes.Stream, now time.Time) IdempotentResult {
+	return IdempotentResult{
+		RequestHash: idempotency.RequestHash,
+		Stream:      stream,
+		ExpiresAt:   now.Add(IdempotencyWindow),
+	}
+}
+
+func NewIdempotentStreamsResult(idempotency Idempotency, streams []estypes.Stream, now time.Time) IdempotentResult {
+	return IdempotentResult{
+		RequestHash: idempotency.RequestHash,
+		Streams:     streams,
+		ExpiresAt:   now.Add(IdempotencyWindow),
+	}
+}
+
+func (r IdempotentResult) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
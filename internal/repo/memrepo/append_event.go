@@ -0,0 +1,71 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"time"
+)
+
+func (r *MemRepo) AppendEvent(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvent estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	return r.AppendEvents(ctx, streamType, streamId, revision, []estypes.NewEsEvent{newEvent}, options)
+}
+
+func (r *MemRepo) AppendEvents(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvents []estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	if len(newEvents) == 0 {
+		return estypes.Stream{}, fmt.Errorf("no events to append")
+	}
+
+	now := time.Now()
+	stream := estypes.Stream{
+		StreamId:   streamId,
+		StreamType: streamType,
+		Revision:   revision + len(newEvents) - 1,
+		UpdatedAt:  now,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.streams[streamId]
+	if !exists {
+		err := fmt.Errorf("stream not found [%s]", streamId)
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	err := current.ShouldHaveRevision(revision - 1)
+	if err != nil {
+		return estypes.Stream{}, eserror.NewDataConflictError(err)
+	}
+
+	err = current.ShouldNotBeDeleted()
+	if err != nil {
+		return estypes.Stream{}, eserror.NewDataConflictError(err)
+	}
+
+	err = r.checkIdempotency(options.Idempotency, now)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	err = r.checkReservations(options.Reservations, streamId)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	stored := current
+	stored.Revision = stream.Revision
+	stored.UpdatedAt = stream.UpdatedAt
+	r.streams[streamId] = stored
+	for i, newEvent := range newEvents {
+		event := estypes.NewEvent(streamId, revision+i, newEvent, now)
+		r.events[streamId] = append(r.events[streamId], r.appendToAll(event))
+	}
+	r.applyReservations(options.Reservations, stream, now)
+	r.rememberResult(options.Idempotency, stream, now)
+
+	return stream, nil
+}
@@ -0,0 +1,65 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"time"
+)
+
+// AppendToStreams checks the revisions of all streams before writing any of them.
+func (r *MemRepo) AppendToStreams(ctx context.Context, appends []repo.StreamAppend, idempotency *repo.Idempotency) ([]estypes.Stream, error) {
+	err := repo.CheckStreamAppends(appends)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range appends {
+		current, exists := r.streams[a.StreamId]
+		if !exists {
+			err = fmt.Errorf("stream not found [%s]", a.StreamId)
+			return nil, eserror.NewNotFoundError(err)
+		}
+
+		err = current.ShouldHaveRevision(a.Revision - 1)
+		if err != nil {
+			return nil, eserror.NewStreamConflictError(err, a.Conflict())
+		}
+
+		err = current.ShouldNotBeDeleted()
+		if err != nil {
+			return nil, eserror.NewStreamConflictError(err, a.Conflict())
+		}
+	}
+
+	err = r.checkIdempotency(idempotency, now)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make([]estypes.Stream, 0, len(appends))
+	for _, a := range appends {
+		stream := a.UpdatedStream(now)
+
+		stored := r.streams[a.StreamId]
+		stored.Revision = stream.Revision
+		stored.UpdatedAt = stream.UpdatedAt
+		r.streams[a.StreamId] = stored
+		for i, newEvent := range a.Events {
+			event := estypes.NewEvent(a.StreamId, a.Revision+i, newEvent, now)
+			r.events[a.StreamId] = append(r.events[a.StreamId], r.appendToAll(event))
+		}
+
+		streams = append(streams, stream)
+	}
+	r.rememberStreamsResult(idempotency, streams, now)
+
+	return streams, nil
+}
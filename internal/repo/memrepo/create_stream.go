@@ -0,0 +1,59 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"time"
+)
+
+func (r *MemRepo) CreateStream(ctx context.Context, streamType string, initialEvent estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	return r.CreateStreamWithId(ctx, streamType, uuid.New(), initialEvent, options)
+}
+
+func (r *MemRepo) CreateStreamWithId(ctx context.Context, streamType string, streamId uuid.UUID, initialEvent estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	return r.CreateStreamWithEvents(ctx, streamType, streamId, []estypes.NewEsEvent{initialEvent}, options)
+}
+
+func (r *MemRepo) CreateStreamWithEvents(ctx context.Context, streamType string, streamId uuid.UUID, newEvents []estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	if len(newEvents) == 0 {
+		return estypes.Stream{}, fmt.Errorf("no events to create stream with")
+	}
+
+	now := time.Now()
+	stream := estypes.NewStream(streamId, streamType, now)
+	stream.Revision = len(newEvents)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.streams[streamId]; exists {
+		err := fmt.Errorf("stream already exists [%s]", streamId)
+		return estypes.Stream{}, eserror.NewDataConflictError(err)
+	}
+
+	err := r.checkIdempotency(options.Idempotency, now)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	err = r.checkReservations(options.Reservations, streamId)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	r.streams[streamId] = stream
+	events := make([]estypes.Event, 0, len(newEvents))
+	for i, newEvent := range newEvents {
+		event := estypes.NewEvent(streamId, i+1, newEvent, now)
+		events = append(events, r.appendToAll(event))
+	}
+	r.events[streamId] = events
+	r.applyReservations(options.Reservations, stream, now)
+	r.rememberResult(options.Idempotency, stream, now)
+
+	return stream, nil
+}
@@ -0,0 +1,40 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"time"
+)
+
+func (r *MemRepo) DeleteStream(_ context.Context, streamId uuid.UUID, deletion string) (estypes.Stream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stream, exists := r.streams[streamId]
+	if !exists {
+		err := fmt.Errorf("stream not found [%s]", streamId)
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	if deletion == estypes.DeletionSoft {
+		err := stream.ShouldAllowSoftDeletion()
+		if err != nil {
+			return estypes.Stream{}, eserror.NewDataConflictError(err)
+		}
+	}
+
+	stream.Deleted = deletion
+	stream.UpdatedAt = time.Now()
+	r.streams[streamId] = stream
+	r.releaseStreamReservations(streamId)
+
+	if deletion == estypes.DeletionHard {
+		delete(r.events, streamId)
+		delete(r.snapshots, streamId)
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,54 @@
+package memrepo
+
+import (
+	"context"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"time"
+)
+
+func (r *MemRepo) GetAllEvents(_ context.Context, afterPosition int, limit int) (estypes.AllEventPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pageSize := r.pageSize
+	if limit > 0 {
+		pageSize = min(pageSize, limit)
+	}
+
+	events := make([]estypes.Event, 0)
+	lastEvaluatedPosition := max(afterPosition, 0)
+	end := min(lastEvaluatedPosition+pageSize, len(r.all))
+	now := time.Now()
+
+	for position := lastEvaluatedPosition + 1; position <= end; position++ {
+		ref := r.all[position-1]
+		lastEvaluatedPosition = position
+
+		event, ok := r.findEvent(ref.streamId, ref.revision)
+		if !ok {
+			continue
+		}
+		stream := r.streams[ref.streamId]
+		if !stream.Serves(event, now) {
+			continue
+		}
+		event.StreamType = stream.StreamType
+		events = append(events, event)
+	}
+
+	page := estypes.AllEventPage{
+		Events:                events,
+		HasMore:               lastEvaluatedPosition < len(r.all),
+		LastEvaluatedPosition: lastEvaluatedPosition,
+	}
+
+	return page, nil
+}
+
+// appendToAll gives the event the next position of the global log.
+func (r *MemRepo) appendToAll(event estypes.Event) estypes.Event {
+	r.all = append(r.all, eventRef{streamId: event.StreamId, revision: event.Revision})
+	event.Position = len(r.all)
+
+	return event
+}
@@ -0,0 +1,77 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"slices"
+	"time"
+)
+
+func (r *MemRepo) GetCategoryEvents(_ context.Context, streamType string, createdAfter time.Time, nextPageKey string, limit int) (estypes.CategoryEventPage, error) {
+	if nextPageKey != "" {
+		_, _, err := repo.ParseCategoryKey(nextPageKey)
+		if err != nil {
+			return estypes.CategoryEventPage{}, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pageSize := r.pageSize
+	if limit > 0 {
+		pageSize = min(pageSize, limit)
+	}
+
+	type categoryEvent struct {
+		key   string
+		event estypes.Event
+	}
+
+	startKey, isNextPageKey := repo.CategoryStartKey(createdAfter, nextPageKey)
+	categoryEvents := make([]categoryEvent, 0)
+	now := time.Now()
+	for streamId, stream := range r.streams {
+		if stream.StreamType != streamType {
+			continue
+		}
+
+		for _, event := range r.events[streamId] {
+			key := repo.CategoryKey(event.CreatedAt, event.Revision, streamId)
+			if key < startKey || (isNextPageKey && key == startKey) {
+				continue
+			}
+			if !stream.Serves(event, now) {
+				continue
+			}
+			event.StreamType = stream.StreamType
+			categoryEvents = append(categoryEvents, categoryEvent{key: key, event: event})
+		}
+	}
+	slices.SortFunc(categoryEvents, func(a, b categoryEvent) int {
+		return cmp.Compare(a.key, b.key)
+	})
+
+	hasMore := len(categoryEvents) > pageSize
+	if hasMore {
+		categoryEvents = categoryEvents[:pageSize]
+	}
+
+	events := make([]estypes.Event, 0, len(categoryEvents))
+	for _, categoryEvent := range categoryEvents {
+		events = append(events, categoryEvent.event)
+		nextPageKey = categoryEvent.key
+	}
+
+	page := estypes.CategoryEventPage{
+		Events:  events,
+		HasMore: hasMore,
+	}
+	if nextPageKey != "" {
+		page.NextPageKey = &nextPageKey
+	}
+
+	return page, nil
+}
@@ -0,0 +1,32 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+func (r *MemRepo) GetEvent(_ context.Context, streamId uuid.UUID, revision int) (estypes.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	event, ok := r.findEvent(streamId, revision)
+	if !ok {
+		err := fmt.Errorf("event not found [%s::%d]", streamId, revision)
+		return estypes.Event{}, eserror.NewNotFoundError(err)
+	}
+
+	return event, nil
+}
+
+func (r *MemRepo) findEvent(streamId uuid.UUID, revision int) (estypes.Event, bool) {
+	for _, event := range r.events[streamId] {
+		if event.Revision == revision {
+			return event, true
+		}
+	}
+
+	return estypes.Event{}, false
+}
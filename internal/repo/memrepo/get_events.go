@@ -0,0 +1,65 @@
+package memrepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"slices"
+	"time"
+)
+
+func (r *MemRepo) GetEvents(_ context.Context, streamId uuid.UUID, eventRange repo.EventRange) (estypes.EventPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]estypes.Event, 0)
+	var lastEvaluatedRevision int
+	hasMore := false
+
+	stream := r.streams[streamId]
+	lowest, highest := eventRange.Revisions(stream)
+	pageSize := r.pageSize
+	if eventRange.Limit > 0 {
+		pageSize = min(pageSize, eventRange.Limit)
+	}
+	now := time.Now()
+
+	streamEvents := slices.Values(r.events[streamId])
+	if eventRange.Backward {
+		streamEvents = func(yield func(estypes.Event) bool) {
+			for _, event := range slices.Backward(r.events[streamId]) {
+				if !yield(event) {
+					return
+				}
+			}
+		}
+	}
+
+	evaluated := 0
+	for event := range streamEvents {
+		if event.Revision < lowest || event.Revision > highest {
+			continue
+		}
+
+		if evaluated == pageSize {
+			hasMore = true
+			break
+		}
+
+		evaluated++
+		lastEvaluatedRevision = event.Revision
+		if stream.Metadata.IsExpired(event.CreatedAt, now) || !eventRange.Selects(event.EventType) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	page := estypes.EventPage{
+		Events:                events,
+		HasMore:               hasMore,
+		LastEvaluatedRevision: lastEvaluatedRevision,
+	}
+
+	return page, nil
+}
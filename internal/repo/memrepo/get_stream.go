@@ -0,0 +1,22 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+func (r *MemRepo) GetStream(_ context.Context, streamId uuid.UUID) (estypes.Stream, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stream, exists := r.streams[streamId]
+	if !exists {
+		err := fmt.Errorf("stream not found")
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	return stream, nil
+}
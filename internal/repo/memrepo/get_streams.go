@@ -0,0 +1,56 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"slices"
+	"time"
+)
+
+func (r *MemRepo) GetStreams(_ context.Context, streamType string, updatedAfter time.Time, streamNextPageKey string) (estypes.StreamPage, error) {
+	var after *estypes.Stream
+	if streamNextPageKey != "" {
+		cursor, err := repo.ParseStreamCursor(streamNextPageKey)
+		if err != nil {
+			return estypes.StreamPage{}, fmt.Errorf("failed to parse next page key: %w", err)
+		}
+		after = &estypes.Stream{StreamId: cursor.StreamId, UpdatedAt: cursor.UpdatedAt}
+	}
+
+	r.mu.RLock()
+	matching := make([]estypes.Stream, 0)
+	for _, stream := range r.streams {
+		if stream.StreamType != streamType || stream.UpdatedAt.Before(updatedAfter) {
+			continue
+		}
+		if after != nil && compareStreams(*after, stream) >= 0 {
+			continue
+		}
+		matching = append(matching, stream)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(matching, compareStreams)
+
+	page := estypes.StreamPage{
+		Streams: matching,
+		HasMore: len(matching) > r.pageSize,
+	}
+	if page.HasMore {
+		page.Streams = matching[:r.pageSize]
+		newNextPageKey := repo.NewStreamCursor(page.Streams[r.pageSize-1]).String()
+		page.NextPageKey = &newNextPageKey
+	}
+
+	return page, nil
+}
+
+func compareStreams(a, b estypes.Stream) int {
+	return cmp.Or(
+		a.UpdatedAt.Compare(b.UpdatedAt),
+		cmp.Compare(a.StreamId.String(), b.StreamId.String()),
+	)
+}
@@ -0,0 +1,56 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"time"
+)
+
+func (r *MemRepo) GetIdempotentResult(_ context.Context, key string) (repo.IdempotentResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result, exists := r.idempotentResults[key]
+	if !exists || result.IsExpired(time.Now()) {
+		err := fmt.Errorf("idempotent result not found")
+		return repo.IdempotentResult{}, eserror.NewNotFoundError(err)
+	}
+
+	return result, nil
+}
+
+// checkIdempotency should be called under the write lock before the write.
+func (r *MemRepo) checkIdempotency(idempotency *repo.Idempotency, now time.Time) error {
+	if idempotency == nil {
+		return nil
+	}
+
+	existing, exists := r.idempotentResults[idempotency.Key]
+	if exists && !existing.IsExpired(now) {
+		err := fmt.Errorf("idempotency key already used [%s]", idempotency.Key)
+		return eserror.NewDataConflictError(err)
+	}
+
+	return nil
+}
+
+// rememberResult should be called under the write lock after the write.
+func (r *MemRepo) rememberResult(idempotency *repo.Idempotency, stream estypes.Stream, now time.Time) {
+	if idempotency == nil {
+		return
+	}
+
+	r.idempotentResults[idempotency.Key] = repo.NewIdempotentResult(*idempotency, stream, now)
+}
+
+// rememberStreamsResult is rememberResult of a multi-stream write.
+func (r *MemRepo) rememberStreamsResult(idempotency *repo.Idempotency, streams []estypes.Stream, now time.Time) {
+	if idempotency == nil {
+		return
+	}
+
+	r.idempotentResults[idempotency.Key] = repo.NewIdempotentStreamsResult(*idempotency, streams, now)
+}
@@ -0,0 +1,45 @@
+package memrepo
+
+import (
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"sync"
+)
+
+const defaultPageSize = 100
+
+// MemRepo is an in-memory storage backend of the Event Store.
+//
+// It keeps the same guarantees as the DynamoDB backend and is meant
+// for tests and for running the Event Store offline.
+// All data is lost once the process exits.
+type MemRepo struct {
+	mu                sync.RWMutex
+	streams           map[uuid.UUID]estypes.Stream
+	events            map[uuid.UUID][]estypes.Event
+	all               []eventRef
+	idempotentResults map[string]repo.IdempotentResult
+	snapshots         map[uuid.UUID]estypes.Snapshot
+	reservations      map[estypes.ReservationKey]estypes.Reservation
+	pageSize          int
+}
+
+// eventRef is an entry of the global log, the position of an event is its index + 1.
+type eventRef struct {
+	streamId uuid.UUID
+	revision int
+}
+
+var _ repo.EsStore = (*MemRepo)(nil)
+
+func NewMemRepo() *MemRepo {
+	return &MemRepo{
+		streams:           make(map[uuid.UUID]estypes.Stream),
+		events:            make(map[uuid.UUID][]estypes.Event),
+		idempotentResults: make(map[string]repo.IdempotentResult),
+		snapshots:         make(map[uuid.UUID]estypes.Snapshot),
+		reservations:      make(map[estypes.ReservationKey]estypes.Reservation),
+		pageSize:          defaultPageSize,
+	}
+}
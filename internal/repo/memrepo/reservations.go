@@ -0,0 +1,67 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"time"
+)
+
+func (r *MemRepo) GetReservation(_ context.Context, key estypes.ReservationKey) (estypes.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservation, exists := r.reservations[key]
+	if !exists {
+		err := fmt.Errorf("reservation not found [%s::%s]", key.Namespace, key.Key)
+		return estypes.Reservation{}, eserror.NewNotFoundError(err)
+	}
+
+	return reservation, nil
+}
+
+// checkReservations should be called under the write lock before the write.
+func (r *MemRepo) checkReservations(reservations repo.Reservations, streamId uuid.UUID) error {
+	var conflicts []eserror.ReservationConflict
+	for _, key := range reservations.Claim {
+		existing, exists := r.reservations[key]
+		if exists && existing.StreamId != streamId {
+			conflicts = append(conflicts, eserror.ReservationConflict{Namespace: key.Namespace, Key: key.Key})
+		}
+	}
+	for _, key := range reservations.Release {
+		existing, exists := r.reservations[key]
+		if !exists || existing.StreamId != streamId {
+			conflicts = append(conflicts, eserror.ReservationConflict{Namespace: key.Namespace, Key: key.Key})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		err := fmt.Errorf("reservations are held by other streams [%s]", streamId)
+		return eserror.NewReservationConflictError(err, conflicts...)
+	}
+
+	return nil
+}
+
+// applyReservations should be called under the write lock after the write.
+func (r *MemRepo) applyReservations(reservations repo.Reservations, stream estypes.Stream, now time.Time) {
+	for _, key := range reservations.Claim {
+		r.reservations[key] = estypes.NewReservation(key, stream, now)
+	}
+	for _, key := range reservations.Release {
+		delete(r.reservations, key)
+	}
+}
+
+// releaseStreamReservations should be called under the write lock when the stream is deleted.
+func (r *MemRepo) releaseStreamReservations(streamId uuid.UUID) {
+	for key, reservation := range r.reservations {
+		if reservation.StreamId == streamId {
+			delete(r.reservations, key)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+func (r *MemRepo) SetStreamMetadata(_ context.Context, streamId uuid.UUID, metadata estypes.StreamMetadata) (estypes.Stream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stream, exists := r.streams[streamId]
+	if !exists {
+		err := fmt.Errorf("stream not found [%s]", streamId)
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	err := stream.ShouldNotBeDeleted()
+	if err != nil {
+		return estypes.Stream{}, eserror.NewDataConflictError(err)
+	}
+
+	stream.Metadata = &metadata
+	r.streams[streamId] = stream
+
+	return stream, nil
+}
@@ -0,0 +1,23 @@
+package memrepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// ShredPayloads drops the payloads of the stream.
+// Nothing is encrypted in memory, so the payloads are simply forgotten.
+func (r *MemRepo) ShredPayloads(_ context.Context, streamId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.events[streamId] {
+		event := &r.events[streamId][i]
+		event.Payload = ""
+		event.Shredded = true
+	}
+
+	delete(r.snapshots, streamId)
+
+	return nil
+}
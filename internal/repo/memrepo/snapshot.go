@@ -0,0 +1,37 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+func (r *MemRepo) SaveSnapshot(_ context.Context, snapshot estypes.Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.snapshots[snapshot.StreamId]
+	if exists && existing.Revision > snapshot.Revision {
+		err := fmt.Errorf("stream [%s] already has a snapshot of a later revision", snapshot.StreamId)
+		return eserror.NewDataConflictError(err)
+	}
+
+	r.snapshots[snapshot.StreamId] = snapshot
+
+	return nil
+}
+
+func (r *MemRepo) GetSnapshot(_ context.Context, streamId uuid.UUID) (estypes.Snapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, exists := r.snapshots[streamId]
+	if !exists {
+		err := fmt.Errorf("snapshot not found")
+		return estypes.Snapshot{}, eserror.NewNotFoundError(err)
+	}
+
+	return snapshot, nil
+}
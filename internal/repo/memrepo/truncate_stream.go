@@ -0,0 +1,37 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+func (r *MemRepo) TruncateStream(_ context.Context, streamId uuid.UUID, beforeRevision int) (estypes.Stream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stream, exists := r.streams[streamId]
+	if !exists {
+		err := fmt.Errorf("stream not found [%s]", streamId)
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	err := stream.ShouldAllowTruncation(beforeRevision)
+	if err != nil {
+		return estypes.Stream{}, eserror.NewDataConflictError(err)
+	}
+
+	stream.TruncatedBefore = beforeRevision
+	r.streams[streamId] = stream
+
+	events := r.events[streamId]
+	kept := 0
+	for kept < len(events) && events[kept].Revision < beforeRevision {
+		kept++
+	}
+	r.events[streamId] = append([]estypes.Event(nil), events[kept:]...)
+
+	return stream, nil
+}
@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"expvar"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"sync"
+)
+
+const (
+	CodecGzip = "gzip"
+	CodecZstd = "zstd"
+)
+
+// DefaultCompressionThreshold is the payload size below which compression rarely pays off.
+const DefaultCompressionThreshold = 1024
+
+// compressionBytesSaved is the metric of how much smaller the stored payloads are due to compression.
+// It is published with expvar.
+var compressionBytesSaved = expvar.NewInt("payloadCompressionBytesSaved")
+
+// PayloadCompression compresses payloads larger than the threshold with the codec.
+//
+// The codec is recorded in the event record, so records written with another codec
+// or without compression stay readable.
+// A compressed payload is kept only when it is actually smaller.
+// A nil PayloadCompression keeps payloads uncompressed.
+type PayloadCompression struct {
+	codec     string
+	threshold int
+}
+
+func NewPayloadCompression(codec string, threshold int) (*PayloadCompression, error) {
+	if _, ok := payloadCodecs[codec]; !ok {
+		return nil, fmt.Errorf("unsupported payload codec [%s]", codec)
+	}
+
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+
+	return &PayloadCompression{
+		codec:     codec,
+		threshold: threshold,
+	}, nil
+}
+
+// Compress replaces the payload of a new event record with its compressed form.
+func (c *PayloadCompression) Compress(dbEvent *DbEvent) error {
+	if c == nil || len(dbEvent.Payload.Text) <= c.threshold {
+		return nil
+	}
+
+	compressed, err := payloadCodecs[c.codec].compress([]byte(dbEvent.Payload.Text))
+	if err != nil {
+		return fmt.Errorf("failed to compress payload [%s::%d]: %w", dbEvent.Pk, dbEvent.Sk, err)
+	}
+
+	saved := len(dbEvent.Payload.Text) - len(compressed)
+	if saved <= 0 {
+		return nil
+	}
+
+	dbEvent.Payload = DbPayload{}
+	dbEvent.PayloadCodec = c.codec
+	dbEvent.CompressedPayload = compressed
+	compressionBytesSaved.Add(int64(saved))
+
+	return nil
+}
+
+func decompressPayload(dbEvent DbEvent) (string, error) {
+	codec, ok := payloadCodecs[dbEvent.PayloadCodec]
+	if !ok {
+		return "", fmt.Errorf("unsupported payload codec [%s]", dbEvent.PayloadCodec)
+	}
+
+	payload, err := codec.decompress(dbEvent.CompressedPayload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress payload: %w", err)
+	}
+
+	return string(payload), nil
+}
+
+type payloadCodec interface {
+	compress(data []byte) ([]byte, error)
+	decompress(data []byte) ([]byte, error)
+}
+
+var payloadCodecs = map[string]payloadCodec{
+	CodecGzip: gzipCodec{},
+	CodecZstd: &zstdCodec{},
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+// zstdCodec shares one encoder and one decoder, both are safe for concurrent EncodeAll and DecodeAll.
+type zstdCodec struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func (c *zstdCodec) init() error {
+	c.once.Do(func() {
+		c.encoder, c.err = zstd.NewWriter(nil)
+		if c.err != nil {
+			return
+		}
+		c.decoder, c.err = zstd.NewReader(nil)
+	})
+
+	return c.err
+}
+
+func (c *zstdCodec) compress(data []byte) ([]byte, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCodec) decompress(data []byte) ([]byte, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decoder.DecodeAll(data, nil)
+}
@@ -0,0 +1,220 @@
+package repo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/keyprovider"
+	"sync"
+	"time"
+)
+
+const CipherAes256Gcm = "aes-256-gcm"
+
+// keyCacheTtl bounds how long a deleted key may still be used for reading by other instances of the Event Store.
+const keyCacheTtl = time.Minute
+
+// maxCachedKeys keeps the key cache small, it is simply dropped when full.
+const maxCachedKeys = 1000
+
+// PayloadEncryption encrypts payloads with the data key of their stream.
+//
+// Once the key is deleted (see Shred), the payloads of the stream are reported as shredded on read.
+// A nil PayloadEncryption keeps payloads unencrypted.
+type PayloadEncryption struct {
+	keys keyprovider.KeyProvider
+
+	mu       sync.Mutex
+	keyCache map[uuid.UUID]cachedKey
+}
+
+type cachedKey struct {
+	dataKey   keyprovider.DataKey
+	expiresAt time.Time
+}
+
+func NewPayloadEncryption(keys keyprovider.KeyProvider) *PayloadEncryption {
+	return &PayloadEncryption{
+		keys:     keys,
+		keyCache: make(map[uuid.UUID]cachedKey),
+	}
+}
+
+// Encrypt replaces the payloads of new event records with their encrypted form.
+// The data key of each stream is taken from the key cache, and only resolved with the key provider when not cached.
+func (e *PayloadEncryption) Encrypt(ctx context.Context, dbEvents []DbEvent) error {
+	if e == nil {
+		return nil
+	}
+
+	dataKeys := make(map[string]keyprovider.DataKey)
+	for i := range dbEvents {
+		dbEvent := &dbEvents[i]
+
+		dataKey, ok := dataKeys[dbEvent.Pk]
+		if !ok {
+			var err error
+			dataKey, err = e.writeKey(ctx, dbEvent.Pk)
+			if err != nil {
+				return err
+			}
+			dataKeys[dbEvent.Pk] = dataKey
+		}
+
+		aead, err := newAead(dataKey.Key)
+		if err != nil {
+			return err
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		_, err = rand.Read(nonce)
+		if err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
+
+		encrypted := aead.Seal(nonce, nonce, dbEvent.storedPayload(), payloadAad(dbEvent))
+
+		dbEvent.setStoredPayload(nil)
+		dbEvent.PayloadCipher = CipherAes256Gcm
+		dbEvent.PayloadKeyId = dataKey.KeyId
+		dbEvent.EncryptedPayload = encrypted
+	}
+
+	return nil
+}
+
+// writeKey returns the cached key of the stream, or creates the key when the stream has none yet.
+//
+// Another instance of the Event Store may keep writing with a cached key deleted by Shred for up to keyCacheTtl,
+// the payloads it writes meanwhile are shredded as well.
+func (e *PayloadEncryption) writeKey(ctx context.Context, pk string) (keyprovider.DataKey, error) {
+	streamId, err := uuid.Parse(pk)
+	if err != nil {
+		return keyprovider.DataKey{}, fmt.Errorf("failed to parse streamId: %w", err)
+	}
+
+	e.mu.Lock()
+	cached, ok := e.keyCache[streamId]
+	e.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.dataKey, nil
+	}
+
+	dataKey, err := e.keys.CreateKey(ctx, streamId)
+	if err != nil {
+		return keyprovider.DataKey{}, fmt.Errorf("failed to get data key: %w", err)
+	}
+	e.cacheKey(streamId, dataKey)
+
+	return dataKey, nil
+}
+
+// Decrypt brings back the payload of an event record, or marks it shredded when the key is deleted.
+func (e *PayloadEncryption) Decrypt(ctx context.Context, dbEvent *DbEvent) error {
+	if dbEvent.PayloadCipher == "" {
+		return nil
+	}
+	if dbEvent.PayloadCipher != CipherAes256Gcm {
+		return fmt.Errorf("unsupported payload cipher [%s]", dbEvent.PayloadCipher)
+	}
+	if e == nil {
+		return fmt.Errorf("payload [%s::%d] is encrypted, but no key provider is configured", dbEvent.Pk, dbEvent.Sk)
+	}
+
+	streamId, err := uuid.Parse(dbEvent.Pk)
+	if err != nil {
+		return fmt.Errorf("failed to parse streamId: %w", err)
+	}
+
+	// the stream may have got a new key after the one of this payload was deleted
+	dataKey, err := e.readKey(ctx, streamId, dbEvent.PayloadKeyId)
+	if errors.As(err, new(*eserror.NotFoundError)) || (err == nil && dataKey.KeyId != dbEvent.PayloadKeyId) {
+		dbEvent.PayloadCipher = ""
+		dbEvent.PayloadKeyId = ""
+		dbEvent.EncryptedPayload = nil
+		dbEvent.Shredded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAead(dataKey.Key)
+	if err != nil {
+		return err
+	}
+
+	encrypted := dbEvent.EncryptedPayload
+	if len(encrypted) < aead.NonceSize() {
+		return fmt.Errorf("encrypted payload [%s::%d] is too short", dbEvent.Pk, dbEvent.Sk)
+	}
+
+	nonce, ciphertext := encrypted[:aead.NonceSize()], encrypted[aead.NonceSize():]
+	payload, err := aead.Open(nil, nonce, ciphertext, payloadAad(dbEvent))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt payload [%s::%d]: %w", dbEvent.Pk, dbEvent.Sk, err)
+	}
+
+	dbEvent.PayloadCipher = ""
+	dbEvent.PayloadKeyId = ""
+	dbEvent.EncryptedPayload = nil
+	dbEvent.setStoredPayload(payload)
+
+	return nil
+}
+
+// Shred deletes the data key of the stream, so that its payloads cannot be read anymore.
+func (e *PayloadEncryption) Shred(ctx context.Context, streamId uuid.UUID) error {
+	e.mu.Lock()
+	delete(e.keyCache, streamId)
+	e.mu.Unlock()
+
+	return e.keys.DeleteKey(ctx, streamId)
+}
+
+// readKey returns the cached key of the stream, unless the payload needs another one.
+func (e *PayloadEncryption) readKey(ctx context.Context, streamId uuid.UUID, keyId string) (keyprovider.DataKey, error) {
+	e.mu.Lock()
+	cached, ok := e.keyCache[streamId]
+	e.mu.Unlock()
+	if ok && cached.dataKey.KeyId == keyId && time.Now().Before(cached.expiresAt) {
+		return cached.dataKey, nil
+	}
+
+	dataKey, err := e.keys.GetKey(ctx, streamId)
+	if err != nil {
+		return keyprovider.DataKey{}, fmt.Errorf("failed to get data key: %w", err)
+	}
+	e.cacheKey(streamId, dataKey)
+
+	return dataKey, nil
+}
+
+func (e *PayloadEncryption) cacheKey(streamId uuid.UUID, dataKey keyprovider.DataKey) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.keyCache) >= maxCachedKeys {
+		clear(e.keyCache)
+	}
+	e.keyCache[streamId] = cachedKey{dataKey: dataKey, expiresAt: time.Now().Add(keyCacheTtl)}
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// payloadAad binds an encrypted payload to its event, so it cannot be moved to another one.
+func payloadAad(dbEvent *DbEvent) []byte {
+	return fmt.Appendf(nil, "%s::%d", dbEvent.Pk, dbEvent.Sk)
+}
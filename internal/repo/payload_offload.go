@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/internal/blobstore"
+)
+
+// DefaultOffloadThreshold leaves enough room for the rest of the event record
+// within the DynamoDB item size limit of 400 KB.
+const DefaultOffloadThreshold = 256 * 1024
+
+// PayloadOffload keeps payloads larger than the threshold in a blob store.
+//
+// The event record then holds only the blob key and the SHA-256 checksum of the payload.
+// Blobs are keyed by stream id, revision and checksum, so a write that loses a revision conflict
+// never overwrites the payload of the winning event, and events with equal payloads never share a blob
+// that the truncation of one of them would delete.
+// A nil PayloadOffload keeps all payloads in the event records.
+type PayloadOffload struct {
+	blobs     blobstore.BlobStore
+	threshold int
+}
+
+func NewPayloadOffload(blobs blobstore.BlobStore, threshold int) *PayloadOffload {
+	if threshold <= 0 {
+		threshold = DefaultOffloadThreshold
+	}
+
+	return &PayloadOffload{
+		blobs:     blobs,
+		threshold: threshold,
+	}
+}
+
+// Offload moves the payload of a new event to the blob store when it is above the threshold.
+// The payload is offloaded in the form it would be kept in the record, i.e. compressed and encrypted.
+func (o *PayloadOffload) Offload(ctx context.Context, dbEvent *DbEvent) error {
+	data := dbEvent.storedPayload()
+	if o == nil || len(data) <= o.threshold {
+		return nil
+	}
+
+	checksum := payloadChecksum(data)
+	key := fmt.Sprintf("%s/%d-%s", dbEvent.Pk, dbEvent.Sk, checksum)
+
+	err := o.blobs.Put(ctx, key, data)
+	if err != nil {
+		return fmt.Errorf("failed to offload payload [%s::%d]: %w", dbEvent.Pk, dbEvent.Sk, err)
+	}
+
+	dbEvent.setStoredPayload(nil)
+	dbEvent.PayloadRef = key
+	dbEvent.PayloadChecksum = checksum
+
+	return nil
+}
+
+// Rehydrate loads an offloaded payload back into the event record.
+func (o *PayloadOffload) Rehydrate(ctx context.Context, dbEvent *DbEvent) error {
+	if dbEvent.PayloadRef == "" {
+		return nil
+	}
+	if o == nil {
+		return fmt.Errorf("payload [%s::%d] is offloaded, but no blob store is configured", dbEvent.Pk, dbEvent.Sk)
+	}
+
+	data, err := o.blobs.Get(ctx, dbEvent.PayloadRef)
+	if err != nil {
+		return fmt.Errorf("failed to load payload [%s::%d]: %w", dbEvent.Pk, dbEvent.Sk, err)
+	}
+
+	if payloadChecksum(data) != dbEvent.PayloadChecksum {
+		return fmt.Errorf("checksum mismatch of payload [%s::%d]", dbEvent.Pk, dbEvent.Sk)
+	}
+
+	dbEvent.setStoredPayload(data)
+
+	return nil
+}
+
+// Discard deletes offloaded payloads by their blob keys.
+func (o *PayloadOffload) Discard(ctx context.Context, payloadRefs []string) error {
+	if len(payloadRefs) == 0 {
+		return nil
+	}
+	if o == nil {
+		return fmt.Errorf("payloads are offloaded, but no blob store is configured")
+	}
+
+	for _, payloadRef := range payloadRefs {
+		err := o.blobs.Delete(ctx, payloadRef)
+		if err != nil {
+			return fmt.Errorf("failed to discard payload: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func payloadChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// PayloadStorage tells how event payloads are kept in the event records.
+//
+// The zero value keeps payloads in the records as they are.
+type PayloadStorage struct {
+	Offload     *PayloadOffload
+	Compression *PayloadCompression
+	Encryption  *PayloadEncryption
+}
+
+// Store prepares the payloads of the new event records of a write:
+// they are compressed, then encrypted, and offloaded to the blob store when still too large.
+func (s PayloadStorage) Store(ctx context.Context, dbEvents []DbEvent) error {
+	for i := range dbEvents {
+		err := s.Compression.Compress(&dbEvents[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.Encryption.Encrypt(ctx, dbEvents)
+	if err != nil {
+		return err
+	}
+
+	for i := range dbEvents {
+		err = s.Offload.Offload(ctx, &dbEvents[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Load brings back an offloaded payload and decrypts it. Compressed payloads are decompressed by IntoEvent.
+func (s PayloadStorage) Load(ctx context.Context, dbEvent *DbEvent) error {
+	err := s.Offload.Rehydrate(ctx, dbEvent)
+	if err != nil {
+		return err
+	}
+
+	return s.Encryption.Decrypt(ctx, dbEvent)
+}
+
+// Shred makes the payloads of the stream unreadable. It needs payload encryption to be configured.
+func (s PayloadStorage) Shred(ctx context.Context, streamId uuid.UUID) error {
+	if s.Encryption == nil {
+		err := fmt.Errorf("payload encryption is not configured")
+		validationErrors := eserror.NewSimpleValidationError("streamId", "payload encryption is not configured")
+		return eserror.NewValidationError(err, validationErrors)
+	}
+
+	return s.Encryption.Shred(ctx, streamId)
+}
+
+// Discard removes what is kept outside the event records of a hard-deleted stream:
+// offloaded payloads and the data key, if payload encryption is configured.
+func (s PayloadStorage) Discard(ctx context.Context, streamId uuid.UUID, payloadRefs []string) error {
+	err := s.Offload.Discard(ctx, payloadRefs)
+	if err != nil {
+		return err
+	}
+
+	if s.Encryption == nil {
+		return nil
+	}
+
+	return s.Encryption.Shred(ctx, streamId)
+}
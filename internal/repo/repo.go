@@ -7,11 +7,13 @@ import (
 type EsRepo struct {
 	dynamoDb  *dynamodb.Client
 	tableName string
+	payloads  PayloadStorage
 }
 
-func NewEsRepo(dynamoDb *dynamodb.Client, tableName string) *EsRepo {
+func NewEsRepo(dynamoDb *dynamodb.Client, tableName string, payloads PayloadStorage) *EsRepo {
 	return &EsRepo{
 		dynamoDb:  dynamoDb,
 		tableName: tableName,
+		payloads:  payloads,
 	}
 }
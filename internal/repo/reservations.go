@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+)
+
+// Reservations are the unique keys claimed and released by a write to a stream, atomically with the write itself.
+//
+// A claim fails with eserror.DataConflictError when another stream holds the key, claiming a key again by its holder
+// is allowed. A release fails the same way when the stream does not hold the key.
+type Reservations struct {
+	Claim   []estypes.ReservationKey
+	Release []estypes.ReservationKey
+}
+
+func (r Reservations) count() int {
+	return len(r.Claim) + len(r.Release)
+}
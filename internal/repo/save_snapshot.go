@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// SaveSnapshot replaces the latest snapshot of the stream.
+// It fails with eserror.DataConflictError when the stored snapshot covers a later revision.
+func (r *EsRepo) SaveSnapshot(ctx context.Context, snapshot estypes.Snapshot) error {
+	snapshotPut, err := prepareSnapshotPut(r.tableName, snapshot)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.dynamoDb.PutItem(ctx, snapshotPut)
+	if err != nil {
+		conditionFailed := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &conditionFailed) {
+			err = fmt.Errorf("stream [%s] already has a snapshot of a later revision: %w", snapshot.StreamId, err)
+			return eserror.NewDataConflictError(err)
+		}
+		return fmt.Errorf("failed to put snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func prepareSnapshotPut(tableName string, snapshot estypes.Snapshot) (*dynamodb.PutItemInput, error) {
+	dbSnapshot := FromSnapshot(snapshot)
+
+	value, err := attributevalue.MarshalMap(dbSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal db snapshot: %w", err)
+	}
+
+	condExpr, err := expression.NewBuilder().WithCondition(
+		expression.AttributeNotExists(expression.Name("PK")).
+			Or(expression.Name("SnapshotRevision").LessThanEqual(expression.Value(snapshot.Revision))),
+	).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build snapshot condition: %w", err)
+	}
+
+	put := &dynamodb.PutItemInput{
+		Item:                      value,
+		TableName:                 aws.String(tableName),
+		ConditionExpression:       condExpr.Condition(),
+		ExpressionAttributeNames:  condExpr.Names(),
+		ExpressionAttributeValues: condExpr.Values(),
+	}
+
+	return put, nil
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// SetStreamMetadata replaces the metadata of the stream, kept in the stream record.
+// It fails with eserror.DataConflictError when the stream is deleted.
+func (r *EsRepo) SetStreamMetadata(ctx context.Context, streamId uuid.UUID, metadata estypes.StreamMetadata) (estypes.Stream, error) {
+	streamUpdate, err := prepareStreamMetadataUpdate(r.tableName, streamId, metadata)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	output, err := r.dynamoDb.UpdateItem(ctx, streamUpdate)
+	if err != nil {
+		conditionFailed := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &conditionFailed) {
+			err = fmt.Errorf("stream [%s] is missing or deleted: %w", streamId, err)
+			return estypes.Stream{}, eserror.NewDataConflictError(err)
+		}
+		return estypes.Stream{}, fmt.Errorf("failed to update stream metadata: %w", err)
+	}
+
+	var dbStream DbStream
+	err = attributevalue.UnmarshalMap(output.Attributes, &dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to unmarshal stream from DB: %w", err)
+	}
+
+	stream, err := IntoStream(dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to convert DbStream into Stream [%s]: %w", streamId, err)
+	}
+
+	return stream, nil
+}
+
+func prepareStreamMetadataUpdate(tableName string, streamId uuid.UUID, metadata estypes.StreamMetadata) (*dynamodb.UpdateItemInput, error) {
+	updateExpr, err := expression.NewBuilder().
+		WithUpdate(expression.Set(expression.Name("Metadata"), expression.Value(fromStreamMetadata(&metadata)))).
+		WithCondition(
+			expression.AttributeExists(expression.Name("PK")).
+				And(expression.AttributeNotExists(expression.Name("Deleted"))),
+		).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update expression: %w", err)
+	}
+
+	streamKeyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: streamId.String(), Sk: 0})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stream key: %w", err)
+	}
+
+	update := &dynamodb.UpdateItemInput{
+		Key:                       streamKeyValue,
+		TableName:                 aws.String(tableName),
+		UpdateExpression:          updateExpr.Update(),
+		ExpressionAttributeNames:  updateExpr.Names(),
+		ExpressionAttributeValues: updateExpr.Values(),
+		ConditionExpression:       updateExpr.Condition(),
+		ReturnValues:              types.ReturnValueAllNew,
+	}
+
+	return update, nil
+}
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/google/uuid"
+)
+
+// ShredPayloads deletes the encryption key of the stream, so that its payloads cannot be read anymore.
+// The snapshot of the stream is deleted as well, since it is derived from the payloads.
+func (r *EsRepo) ShredPayloads(ctx context.Context, streamId uuid.UUID) error {
+	err := r.payloads.Shred(ctx, streamId)
+	if err != nil {
+		return err
+	}
+
+	keyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: streamId.String(), Sk: snapshotSk})
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot key: %w", err)
+	}
+
+	_, err = r.dynamoDb.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		Key:       keyValue,
+		TableName: aws.String(r.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"time"
+)
+
+// EsStore is a storage backend of the Event Store.
+//
+// Every implementation should keep the guarantees of an Event Store:
+// events are appended with sequential revisions without gaps,
+// and conflicting events are rejected with eserror.DataConflictError.
+// Every appended event gets the next position of the global log, in the order the writes complete.
+// Writes remember their result under WriteOptions.Idempotency (or the idempotency of AppendToStreams),
+// and stream creations and appends claim and release the unique keys of their WriteOptions.Reservations.
+type EsStore interface {
+	CreateStream(ctx context.Context, streamType string, initialEvent estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error)
+	CreateStreamWithId(ctx context.Context, streamType string, streamId uuid.UUID, initialEvent estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error)
+	CreateStreamWithEvents(ctx context.Context, streamType string, streamId uuid.UUID, newEvents []estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error)
+	AppendEvent(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvent estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error)
+	AppendEvents(ctx context.Context, streamType string, streamId uuid.UUID, revision int, newEvents []estypes.NewEsEvent, options WriteOptions) (estypes.Stream, error)
+	AppendToStreams(ctx context.Context, appends []StreamAppend, idempotency *Idempotency) ([]estypes.Stream, error)
+	GetStream(ctx context.Context, streamId uuid.UUID) (estypes.Stream, error)
+	GetStreams(ctx context.Context, streamType string, updatedAfter time.Time, streamNextPageKey string) (estypes.StreamPage, error)
+	GetEvent(ctx context.Context, streamId uuid.UUID, revision int) (estypes.Event, error)
+	GetEvents(ctx context.Context, streamId uuid.UUID, eventRange EventRange) (estypes.EventPage, error)
+	GetAllEvents(ctx context.Context, afterPosition int, limit int) (estypes.AllEventPage, error)
+	GetCategoryEvents(ctx context.Context, streamType string, createdAfter time.Time, nextPageKey string, limit int) (estypes.CategoryEventPage, error)
+	GetIdempotentResult(ctx context.Context, key string) (IdempotentResult, error)
+	GetReservation(ctx context.Context, key estypes.ReservationKey) (estypes.Reservation, error)
+	SaveSnapshot(ctx context.Context, snapshot estypes.Snapshot) error
+	GetSnapshot(ctx context.Context, streamId uuid.UUID) (estypes.Snapshot, error)
+	ShredPayloads(ctx context.Context, streamId uuid.UUID) error
+	DeleteStream(ctx context.Context, streamId uuid.UUID, deletion string) (estypes.Stream, error)
+	TruncateStream(ctx context.Context, streamId uuid.UUID, beforeRevision int) (estypes.Stream, error)
+	SetStreamMetadata(ctx context.Context, streamId uuid.UUID, metadata estypes.StreamMetadata) (estypes.Stream, error)
+}
+
+var _ EsStore = (*EsRepo)(nil)
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
 	"strings"
+	"time"
 )
 
 type StreamNextPageKey struct {
@@ -14,13 +18,18 @@ type StreamNextPageKey struct {
 	UpdatedAt  string
 }
 
+// ParseNextPageKey builds the exclusive start key of StreamIndexV2 from the next page key.
 func ParseNextPageKey(nextPageKey string) (map[string]types.AttributeValue, error) {
-	parts := strings.Split(nextPageKey, "|")
+	cursor, err := ParseStreamCursor(nextPageKey)
+	if err != nil {
+		return nil, err
+	}
+
 	streamNextPageKey := StreamNextPageKey{
-		Pk:         parts[0],
+		Pk:         cursor.StreamId.String(),
 		Sk:         0,
-		StreamType: parts[1],
-		UpdatedAt:  parts[2],
+		StreamType: cursor.StreamType,
+		UpdatedAt:  cursor.UpdatedAt.UTC().Format(time.RFC3339Nano),
 	}
 
 	key, err := attributevalue.MarshalMap(streamNextPageKey)
@@ -46,3 +55,59 @@ func FormatNextPageKey(lastEvaluatedKey map[string]types.AttributeValue) (string
 
 	return strings.Join(parts, "|"), nil
 }
+
+// StreamCursor is a position in the stream listing used by the storage backends other than DynamoDB.
+// Its string form is compatible with the DynamoDB next page key.
+type StreamCursor struct {
+	StreamId   uuid.UUID
+	StreamType string
+	UpdatedAt  time.Time
+}
+
+func NewStreamCursor(stream estypes.Stream) StreamCursor {
+	return StreamCursor{
+		StreamId:   stream.StreamId,
+		StreamType: stream.StreamType,
+		UpdatedAt:  stream.UpdatedAt,
+	}
+}
+
+func ParseStreamCursor(nextPageKey string) (StreamCursor, error) {
+	parts := strings.Split(nextPageKey, "|")
+	if len(parts) != 3 {
+		return StreamCursor{}, invalidNextPageKey(fmt.Errorf("unexpected number of parts: %d", len(parts)))
+	}
+
+	streamId, err := uuid.Parse(parts[0])
+	if err != nil {
+		return StreamCursor{}, invalidNextPageKey(err)
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return StreamCursor{}, invalidNextPageKey(err)
+	}
+
+	cursor := StreamCursor{
+		StreamId:   streamId,
+		StreamType: parts[1],
+		UpdatedAt:  updatedAt,
+	}
+
+	return cursor, nil
+}
+
+func (c StreamCursor) String() string {
+	parts := []string{
+		c.StreamId.String(),
+		c.StreamType,
+		c.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	return strings.Join(parts, "|")
+}
+
+func invalidNextPageKey(err error) error {
+	validationErrors := eserror.NewSimpleValidationError(estypes.NextPageKeyParam, "invalid next page key")
+	return eserror.NewValidationError(err, validationErrors)
+}
@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+const conditionalCheckFailed = "ConditionalCheckFailed"
+
+// transactionConflict is the cancellation reason of an item written by another transaction at the same time.
+const transactionConflict = "TransactionConflict"
+
+// fromTransactionError reports a failed condition of a transaction item as a data conflict.
+func fromTransactionError(err error) error {
+	canceled := &types.TransactionCanceledException{}
+	if !errors.As(err, &canceled) {
+		return err
+	}
+
+	for _, reason := range canceled.CancellationReasons {
+		if aws.ToString(reason.Code) == conditionalCheckFailed {
+			return eserror.NewDataConflictError(err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// TruncateStream deletes the events before the given revision, keeping the stream revision intact.
+//
+// The truncation point is recorded in the stream record first, and then the events are deleted in batches,
+// so a truncation may be repeated to finish the removal after a failure.
+// It fails with eserror.DataConflictError when the stream is deleted, has no event of the given revision
+// or is already truncated at a later revision.
+func (r *EsRepo) TruncateStream(ctx context.Context, streamId uuid.UUID, beforeRevision int) (estypes.Stream, error) {
+	streamUpdate, err := prepareStreamTruncation(r.tableName, streamId, beforeRevision)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	output, err := r.dynamoDb.UpdateItem(ctx, streamUpdate)
+	if err != nil {
+		conditionFailed := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &conditionFailed) {
+			err = fmt.Errorf("stream [%s] cannot be truncated before revision [%d]: %w", streamId, beforeRevision, err)
+			return estypes.Stream{}, eserror.NewDataConflictError(err)
+		}
+		return estypes.Stream{}, fmt.Errorf("failed to mark stream as truncated: %w", err)
+	}
+
+	var dbStream DbStream
+	err = attributevalue.UnmarshalMap(output.Attributes, &dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to unmarshal stream from DB: %w", err)
+	}
+
+	stream, err := IntoStream(dbStream)
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to convert DbStream into Stream [%s]: %w", streamId, err)
+	}
+
+	keyCond := expression.Key("PK").Equal(expression.Value(streamId.String())).
+		And(expression.Key("SK").Between(expression.Value(1), expression.Value(beforeRevision-1)))
+
+	payloadRefs, err := r.deleteStreamItems(ctx, streamId, keyCond)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	err = r.payloads.Offload.Discard(ctx, payloadRefs)
+	if err != nil {
+		return estypes.Stream{}, err
+	}
+
+	return stream, nil
+}
+
+func prepareStreamTruncation(tableName string, streamId uuid.UUID, beforeRevision int) (*dynamodb.UpdateItemInput, error) {
+	condition := expression.AttributeExists(expression.Name("PK")).
+		And(expression.AttributeNotExists(expression.Name("Deleted"))).
+		And(expression.Name("StreamRevision").GreaterThanEqual(expression.Value(beforeRevision))).
+		And(expression.Or(
+			expression.AttributeNotExists(expression.Name("TruncatedBefore")),
+			expression.Name("TruncatedBefore").LessThanEqual(expression.Value(beforeRevision)),
+		))
+
+	updateExpr, err := expression.NewBuilder().
+		WithUpdate(expression.Set(expression.Name("TruncatedBefore"), expression.Value(beforeRevision))).
+		WithCondition(condition).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update expression: %w", err)
+	}
+
+	streamKeyValue, err := attributevalue.MarshalMap(dbStreamKey{Pk: streamId.String(), Sk: 0})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stream key: %w", err)
+	}
+
+	update := &dynamodb.UpdateItemInput{
+		Key:                       streamKeyValue,
+		TableName:                 aws.String(tableName),
+		UpdateExpression:          updateExpr.Update(),
+		ExpressionAttributeNames:  updateExpr.Names(),
+		ExpressionAttributeValues: updateExpr.Values(),
+		ConditionExpression:       updateExpr.Condition(),
+		ReturnValues:              types.ReturnValueAllNew,
+	}
+
+	return update, nil
+}
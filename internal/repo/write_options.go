@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"time"
+)
+
+// WriteOptions are the settings of a single write to a stream.
+type WriteOptions struct {
+	// EventTtl makes DynamoDB delete the new event records once older than the TTL, zero keeps them forever.
+	// Other backends keep the events and only hide them on read.
+	EventTtl time.Duration
+	// Reservations are the unique keys claimed and released atomically with the write.
+	Reservations Reservations
+	// Idempotency makes the write remember its result under the idempotency key atomically with the write,
+	// nil does not remember it.
+	Idempotency *Idempotency
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
 	"net/http"
 )
 
@@ -15,7 +16,9 @@ func ExtractStreamId(r *http.Request) (uuid.UUID, error) {
 
 	streamId, err := uuid.Parse(streamIdStr)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid streamId: %w", err)
+		err = fmt.Errorf("invalid streamId: %w", err)
+		validationErrors := eserror.NewSimpleValidationError("streamId", "uuid")
+		return uuid.Nil, eserror.NewValidationError(err, validationErrors)
 	}
 
 	return streamId, nil
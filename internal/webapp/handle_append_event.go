@@ -6,12 +6,31 @@ import (
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
 	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
 	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
 	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
 	"net/http"
 )
 
+// appendEventRequest carries either a single event or a batch of events,
+// batch size is limited by estypes.MaxEventsPerAppend.
+// The append may claim and release unique keys of the stream, see repo.Reservations.
 type appendEventRequest struct {
-	Event *estypes.NewEsEvent `json:"event,omitempty" validate:"required"`
+	Event   *estypes.NewEsEvent      `json:"event,omitempty" validate:"required_without=Events,excluded_with=Events"`
+	Events  []estypes.NewEsEvent     `json:"events,omitempty" validate:"omitempty,min=1,dive"`
+	Claim   []estypes.ReservationKey `json:"claim,omitempty" validate:"omitempty,dive"`
+	Release []estypes.ReservationKey `json:"release,omitempty" validate:"omitempty,dive"`
+}
+
+// validateEventBatch checks the batch size against estypes.MaxEventsPerAppend.
+func validateEventBatch(events []estypes.NewEsEvent) error {
+	if len(events) <= estypes.MaxEventsPerAppend {
+		return nil
+	}
+
+	err := fmt.Errorf("too many events to append [%d]", len(events))
+	validationErrors := eserror.NewSimpleValidationError("events", fmt.Sprintf("max=%d", estypes.MaxEventsPerAppend))
+
+	return eserror.NewValidationError(err, validationErrors)
 }
 
 type appendEventResponse struct {
@@ -34,6 +53,11 @@ func (a *WebApp) HandleAppendEvent(ctx context.Context, r *http.Request) (resp.E
 		return resp.EsResponse{}, err
 	}
 
+	idempotency, err := ExtractIdempotency(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
 	var reqBody appendEventRequest
 	err = ExtractRequestBody(r, &reqBody)
 	if err != nil {
@@ -45,22 +69,53 @@ func (a *WebApp) HandleAppendEvent(ctx context.Context, r *http.Request) (resp.E
 		return resp.EsResponse{}, err
 	}
 
-	stream, err := a.esRepo.GetStream(ctx, streamId)
+	err = validateEventBatch(reqBody.Events)
 	if err != nil {
-		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+		return resp.EsResponse{}, err
 	}
 
-	err = stream.ShouldHaveType(streamType)
+	newEvents := reqBody.Events
+	if reqBody.Event != nil {
+		newEvents = []estypes.NewEsEvent{*reqBody.Event}
+		err = a.validateNewEvents(streamType, "event", newEvents, false)
+	} else {
+		err = a.validateNewEvents(streamType, "events", newEvents, true)
+	}
 	if err != nil {
-		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+		return resp.EsResponse{}, err
 	}
 
-	err = stream.ShouldHaveRevision(streamRevision - 1)
+	err = validateReservations(reqBody.Claim, reqBody.Release, len(newEvents))
 	if err != nil {
-		return resp.EsResponse{}, eserror.NewDataConflictError(err)
+		return resp.EsResponse{}, err
 	}
+	reservations := repo.Reservations{Claim: reqBody.Claim, Release: reqBody.Release}
+
+	stream, replayed, err := a.writeIdempotently(ctx, idempotency, func(idempotency *repo.Idempotency) (estypes.Stream, error) {
+		stream, err := a.esRepo.GetStream(ctx, streamId)
+		if err != nil {
+			return estypes.Stream{}, fmt.Errorf("failed to get stream from event store: %w", err)
+		}
+
+		err = stream.ShouldHaveType(streamType)
+		if err != nil {
+			return estypes.Stream{}, eserror.NewNotFoundError(err)
+		}
+
+		err = stream.ShouldNotBeDeleted()
+		if err != nil {
+			return estypes.Stream{}, eserror.NewGoneError(err)
+		}
+
+		err = stream.ShouldHaveRevision(streamRevision - 1)
+		if err != nil {
+			return estypes.Stream{}, eserror.NewDataConflictError(err)
+		}
+
+		options := repo.WriteOptions{EventTtl: stream.Metadata.EventTtl(), Reservations: reservations, Idempotency: idempotency}
 
-	stream, err = a.esRepo.AppendEvent(ctx, streamType, streamId, streamRevision, *reqBody.Event)
+		return a.esRepo.AppendEvents(ctx, streamType, streamId, streamRevision, newEvents, options)
+	})
 	if err != nil {
 		return resp.EsResponse{}, fmt.Errorf("failed to append event to stream: %w", err)
 	}
@@ -68,7 +123,7 @@ func (a *WebApp) HandleAppendEvent(ctx context.Context, r *http.Request) (resp.E
 	responseBody := appendEventResponse{
 		Stream: stream,
 	}
-	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody))
+	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody), withReplayed(replayed))
 
 	return response, nil
 }
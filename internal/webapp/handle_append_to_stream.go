@@ -0,0 +1,209 @@
+package webapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxAppendAttempts bounds the retries of an append when concurrent appends take the next revision first.
+const maxAppendAttempts = 5
+
+// appendBackoff is the upper bound of the random delay before the second attempt, doubled for every next one.
+const appendBackoff = 20 * time.Millisecond
+
+// expectedRevision is either one of the estypes.ExpectedRevision... modes, or an exact revision when mode is empty.
+type expectedRevision struct {
+	mode     string
+	revision int
+}
+
+// retriable tells whether the append may be retried at the next revision after a conflict.
+func (e expectedRevision) retriable() bool {
+	return e.mode == estypes.ExpectedRevisionAny || e.mode == estypes.ExpectedRevisionStreamExists
+}
+
+type appendToStreamResponse struct {
+	Stream        estypes.Stream `json:"stream"`
+	FirstRevision int            `json:"firstRevision"`
+}
+
+// HandleAppendToStream appends events after the latest event of the stream, as expected by the expected-revision
+// query parameter (estypes.ExpectedRevisionAny by default). The revision of the first event is resolved by the server
+// and returned as firstRevision, the stream has the revision of the last one.
+// When a concurrent append takes the revision first, the append is retried, unless the expected revision is exact.
+// A conflict on the claimed or released keys is not retried.
+func (a *WebApp) HandleAppendToStream(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	if streamId == uuid.Nil {
+		err = errors.New("streamId is nil UUID")
+		validationErrors := eserror.NewSimpleValidationError("streamId", "required")
+		return resp.EsResponse{}, eserror.NewValidationError(err, validationErrors)
+	}
+
+	expected, err := extractExpectedRevision(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	idempotency, err := ExtractIdempotency(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	var reqBody appendEventRequest
+	err = ExtractRequestBody(r, &reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = esvalidate.Validate(&reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = validateEventBatch(reqBody.Events)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	newEvents := reqBody.Events
+	if reqBody.Event != nil {
+		newEvents = []estypes.NewEsEvent{*reqBody.Event}
+		err = a.validateNewEvents(streamType, "event", newEvents, false)
+	} else {
+		err = a.validateNewEvents(streamType, "events", newEvents, true)
+	}
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = validateReservations(reqBody.Claim, reqBody.Release, len(newEvents))
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+	reservations := repo.Reservations{Claim: reqBody.Claim, Release: reqBody.Release}
+
+	stream, replayed, err := a.writeIdempotently(ctx, idempotency, func(idempotency *repo.Idempotency) (estypes.Stream, error) {
+		options := repo.WriteOptions{Reservations: reservations, Idempotency: idempotency}
+		for attempt := 1; ; attempt++ {
+			stream, err := a.appendAtExpectedRevision(ctx, streamType, streamId, expected, newEvents, options)
+
+			conflict := &eserror.DataConflictError{}
+			if err == nil || attempt == maxAppendAttempts || !expected.retriable() || !errors.As(err, &conflict) || isReservationConflict(err) {
+				return stream, err
+			}
+
+			err = waitBeforeAppend(ctx, attempt)
+			if err != nil {
+				return estypes.Stream{}, err
+			}
+		}
+	})
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to append event to stream: %w", err)
+	}
+
+	responseBody := appendToStreamResponse{
+		Stream:        stream,
+		FirstRevision: stream.Revision - len(newEvents) + 1,
+	}
+	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody), withReplayed(replayed))
+
+	return response, nil
+}
+
+// appendAtExpectedRevision makes a single attempt to append the events after the latest event of the stream,
+// or to create the stream with them. The EventTtl of the options is taken from the metadata of the stream.
+func (a *WebApp) appendAtExpectedRevision(ctx context.Context, streamType string, streamId uuid.UUID, expected expectedRevision, newEvents []estypes.NewEsEvent, options repo.WriteOptions) (estypes.Stream, error) {
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+
+	notFound := &eserror.NotFoundError{}
+	if errors.As(err, &notFound) {
+		if expected.mode != estypes.ExpectedRevisionAny && expected.mode != estypes.ExpectedRevisionNoStream {
+			return estypes.Stream{}, err
+		}
+
+		return a.esRepo.CreateStreamWithEvents(ctx, streamType, streamId, newEvents, options)
+	}
+	if err != nil {
+		return estypes.Stream{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return estypes.Stream{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return estypes.Stream{}, eserror.NewGoneError(err)
+	}
+
+	switch expected.mode {
+	case estypes.ExpectedRevisionNoStream:
+		err = fmt.Errorf("stream already exists [%s]", streamId)
+		return estypes.Stream{}, eserror.NewDataConflictError(err)
+	case "":
+		err = stream.ShouldHaveRevision(expected.revision)
+		if err != nil {
+			return estypes.Stream{}, eserror.NewDataConflictError(err)
+		}
+	}
+
+	options.EventTtl = stream.Metadata.EventTtl()
+
+	return a.esRepo.AppendEvents(ctx, streamType, streamId, stream.Revision+1, newEvents, options)
+}
+
+// waitBeforeAppend sleeps for a random part of the backoff, so that concurrent appends to a hot stream
+// do not read the same latest revision again.
+func waitBeforeAppend(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(time.Duration(rand.Int64N(int64(appendBackoff << (attempt - 1)))))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func extractExpectedRevision(r *http.Request) (expectedRevision, error) {
+	value := r.URL.Query().Get("expected-revision")
+	switch value {
+	case "", estypes.ExpectedRevisionAny:
+		return expectedRevision{mode: estypes.ExpectedRevisionAny}, nil
+	case estypes.ExpectedRevisionStreamExists, estypes.ExpectedRevisionNoStream:
+		return expectedRevision{mode: value}, nil
+	}
+
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		err = fmt.Errorf("invalid expected revision [%s]", value)
+		validationErrors := eserror.NewSimpleValidationError("expected-revision", "oneof=any stream-exists no-stream or min=1")
+		return expectedRevision{}, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return expectedRevision{revision: revision}, nil
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
 	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
 	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
 	"net/http"
 )
 
+// createStreamRequest may claim unique keys for the new stream, see repo.Reservations.
 type createStreamRequest struct {
-	InitialEvent *estypes.NewEsEvent `json:"initialEvent,omitempty" validate:"required"`
+	InitialEvent *estypes.NewEsEvent      `json:"initialEvent,omitempty" validate:"required"`
+	Claim        []estypes.ReservationKey `json:"claim,omitempty" validate:"omitempty,dive"`
 }
 
 type createStreamResponse struct {
@@ -23,6 +26,11 @@ func (a *WebApp) HandleCreateStream(ctx context.Context, r *http.Request) (resp.
 		return resp.EsResponse{}, err
 	}
 
+	idempotency, err := ExtractIdempotency(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
 	var reqBody createStreamRequest
 	err = ExtractRequestBody(r, &reqBody)
 	if err != nil {
@@ -34,7 +42,22 @@ func (a *WebApp) HandleCreateStream(ctx context.Context, r *http.Request) (resp.
 		return resp.EsResponse{}, err
 	}
 
-	stream, err := a.esRepo.CreateStream(ctx, streamType, *reqBody.InitialEvent)
+	initialEvent := []estypes.NewEsEvent{*reqBody.InitialEvent}
+	err = a.validateNewEvents(streamType, "initialEvent", initialEvent, false)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = validateReservations(reqBody.Claim, nil, len(initialEvent))
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+	options := repo.WriteOptions{Reservations: repo.Reservations{Claim: reqBody.Claim}}
+
+	stream, replayed, err := a.writeIdempotently(ctx, idempotency, func(idempotency *repo.Idempotency) (estypes.Stream, error) {
+		options.Idempotency = idempotency
+		return a.esRepo.CreateStream(ctx, streamType, initialEvent[0], options)
+	})
 	if err != nil {
 		return resp.EsResponse{}, fmt.Errorf("failed to create stream: %w", err)
 	}
@@ -42,7 +65,7 @@ func (a *WebApp) HandleCreateStream(ctx context.Context, r *http.Request) (resp.
 	responseBody := createStreamResponse{
 		Stream: stream,
 	}
-	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody))
+	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody), withReplayed(replayed))
 
 	return response, nil
 }
@@ -0,0 +1,75 @@
+package webapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+func (a *WebApp) HandleCreateStreamWithId(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	if streamId == uuid.Nil {
+		err = errors.New("streamId is nil UUID")
+		validationErrors := eserror.NewSimpleValidationError("streamId", "required")
+		return resp.EsResponse{}, eserror.NewValidationError(err, validationErrors)
+	}
+
+	idempotency, err := ExtractIdempotency(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	var reqBody createStreamRequest
+	err = ExtractRequestBody(r, &reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = esvalidate.Validate(reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	initialEvent := []estypes.NewEsEvent{*reqBody.InitialEvent}
+	err = a.validateNewEvents(streamType, "initialEvent", initialEvent, false)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = validateReservations(reqBody.Claim, nil, len(initialEvent))
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+	options := repo.WriteOptions{Reservations: repo.Reservations{Claim: reqBody.Claim}}
+
+	stream, replayed, err := a.writeIdempotently(ctx, idempotency, func(idempotency *repo.Idempotency) (estypes.Stream, error) {
+		options.Idempotency = idempotency
+		return a.esRepo.CreateStreamWithId(ctx, streamType, streamId, initialEvent[0], options)
+	})
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to create stream [%s]: %w", streamId, err)
+	}
+
+	responseBody := createStreamResponse{
+		Stream: stream,
+	}
+	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody), withReplayed(replayed))
+
+	return response, nil
+}
@@ -0,0 +1,76 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type deleteStreamResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+// HandleDeleteStream marks the stream as deleted, and with mode=hard also removes its events.
+// A deletion may be repeated, e.g. to finish an interrupted one, and a hard deletion may follow a soft one.
+func (a *WebApp) HandleDeleteStream(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	deletion, err := extractDeletionMode(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	if deletion == estypes.DeletionSoft {
+		err = stream.ShouldAllowSoftDeletion()
+		if err != nil {
+			return resp.EsResponse{}, eserror.NewGoneError(err)
+		}
+	}
+
+	stream, err = a.esRepo.DeleteStream(ctx, streamId, deletion)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to delete stream: %w", err)
+	}
+
+	responseBody := deleteStreamResponse{
+		Stream: stream,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
+
+func extractDeletionMode(r *http.Request) (string, error) {
+	mode := r.URL.Query().Get("mode")
+	switch mode {
+	case "", estypes.DeletionSoft:
+		return estypes.DeletionSoft, nil
+	case estypes.DeletionHard:
+		return estypes.DeletionHard, nil
+	default:
+		err := fmt.Errorf("invalid deletion mode [%s]", mode)
+		validationErrors := eserror.NewSimpleValidationError("mode", "oneof=soft hard")
+		return "", eserror.NewValidationError(err, validationErrors)
+	}
+}
@@ -0,0 +1,46 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type getAllEventsResponse struct {
+	EventPage estypes.AllEventPage `json:"eventPage"`
+}
+
+// HandleGetAllEvents reads the global log of all streams, with query parameters after-position and limit.
+// Every event is upcast with the registry of its stream type.
+func (a *WebApp) HandleGetAllEvents(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	afterPosition, err := extractNonNegativeParam(r, "after-position")
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	limit, err := extractLimitParam(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	eventPage, err := a.esRepo.GetAllEvents(ctx, afterPosition, limit)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get events of global log: %w", err)
+	}
+
+	for i, event := range eventPage.Events {
+		err = a.upcastEvents(event.StreamType, eventPage.Events[i:i+1])
+		if err != nil {
+			return resp.EsResponse{}, err
+		}
+	}
+
+	responseBody := getAllEventsResponse{
+		EventPage: eventPage,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
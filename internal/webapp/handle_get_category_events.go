@@ -0,0 +1,76 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+	"time"
+)
+
+type getCategoryEventsResponse struct {
+	EventPage estypes.CategoryEventPage `json:"eventPage"`
+}
+
+// HandleGetCategoryEvents reads events of all streams of a type in order of creation time,
+// with query parameters created-after, next-page-key and limit.
+func (a *WebApp) HandleGetCategoryEvents(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	createdAfter, err := extractCreatedAfter(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	limit, err := extractLimitParam(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	pageKeyScope := "categories/" + streamType
+	nextPageKey, err := a.pageKeys.open(pageKeyScope, r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	eventPage, err := a.esRepo.GetCategoryEvents(ctx, streamType, createdAfter, nextPageKey, limit)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get category events: %w", err)
+	}
+
+	err = a.upcastEvents(streamType, eventPage.Events)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+	eventPage.NextPageKey = a.pageKeys.seal(pageKeyScope, eventPage.NextPageKey)
+
+	responseBody := getCategoryEventsResponse{
+		EventPage: eventPage,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
+
+func extractCreatedAfter(r *http.Request) (time.Time, error) {
+	zero := time.Unix(0, 0)
+
+	createdAfterStr := r.URL.Query().Get("created-after")
+	if createdAfterStr == "" {
+		return zero, nil
+	}
+
+	createdAfter, err := time.Parse(time.RFC3339Nano, createdAfterStr)
+	if err != nil {
+		err = fmt.Errorf("invalid created-after value: %w", err)
+		validationErrors := eserror.NewSimpleValidationError("created-after", "datetime=RFC3339")
+		return zero, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return createdAfter, nil
+}
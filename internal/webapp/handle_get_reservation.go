@@ -0,0 +1,36 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type getReservationResponse struct {
+	Reservation estypes.Reservation `json:"reservation"`
+}
+
+// HandleGetReservation tells which stream holds a unique key, it responds with 404 when the key is free.
+func (a *WebApp) HandleGetReservation(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	key := estypes.ReservationKey{
+		Namespace: r.PathValue("namespace"),
+		Key:       r.PathValue("key"),
+	}
+	if key.Namespace == "" || key.Key == "" {
+		return resp.EsResponse{}, fmt.Errorf("no reservation namespace or key specified")
+	}
+
+	reservation, err := a.esRepo.GetReservation(ctx, key)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get reservation from event store: %w", err)
+	}
+
+	responseBody := getReservationResponse{
+		Reservation: reservation,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
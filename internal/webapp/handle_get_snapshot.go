@@ -0,0 +1,48 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+func (a *WebApp) HandleGetSnapshot(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream details: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	snapshot, err := a.esRepo.GetSnapshot(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	responseBody := snapshotResponse{
+		Snapshot: snapshot,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
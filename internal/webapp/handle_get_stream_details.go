@@ -34,6 +34,11 @@ func (a *WebApp) HandleGetStreamDetails(ctx context.Context, r *http.Request) (r
 		return resp.EsResponse{}, eserror.NewNotFoundError(err)
 	}
 
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
 	responseBody := getStreamDetailsResponse{
 		Stream: stream,
 	}
@@ -0,0 +1,70 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+	"time"
+)
+
+type getEventResponse struct {
+	Event estypes.Event `json:"event"`
+}
+
+func (a *WebApp) HandleGetStreamEvent(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	revision, err := ExtractStreamRevision(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream details: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	event, err := a.esRepo.GetEvent(ctx, streamId, revision)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	if stream.Hides(event, time.Now()) {
+		err = fmt.Errorf("event [%s::%d] is truncated or hidden by stream metadata", streamId, revision)
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	events := []estypes.Event{event}
+	err = a.upcastEvents(streamType, events)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	responseBody := getEventResponse{
+		Event: events[0],
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
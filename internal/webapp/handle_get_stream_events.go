@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"github.com/ilia-tolliu/serverless-event-store/estypes"
 	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
 	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 type getEventsResponse struct {
@@ -25,7 +28,7 @@ func (a *WebApp) HandleGetStreamEvents(ctx context.Context, r *http.Request) (re
 		return resp.EsResponse{}, err
 	}
 
-	afterRevision, err := extractAfterRevision(r)
+	eventRange, err := extractEventRange(r)
 	if err != nil {
 		return resp.EsResponse{}, err
 	}
@@ -40,11 +43,25 @@ func (a *WebApp) HandleGetStreamEvents(ctx context.Context, r *http.Request) (re
 		return resp.EsResponse{}, eserror.NewNotFoundError(err)
 	}
 
-	eventPage, err := a.esRepo.GetEvents(ctx, streamId, afterRevision)
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	eventPage, err := a.esRepo.GetEvents(ctx, streamId, eventRange)
 	if err != nil {
 		return resp.EsResponse{}, fmt.Errorf("failed to get events: %w", err)
 	}
 
+	err = a.upcastEvents(streamType, eventPage.Events)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	if requestedFrom(eventRange) < stream.TruncatedBefore {
+		eventPage.TruncatedBefore = stream.TruncatedBefore
+	}
+
 	responseBody := getEventsResponse{
 		EventPage: eventPage,
 	}
@@ -53,16 +70,106 @@ func (a *WebApp) HandleGetStreamEvents(ctx context.Context, r *http.Request) (re
 	return response, nil
 }
 
-func extractAfterRevision(r *http.Request) (int, error) {
-	afterRevisionStr := r.URL.Query().Get("after-revision")
-	if afterRevisionStr == "" {
+// maxEventTypes limits the event-types filter, DynamoDB allows at most 100 operands of IN.
+const maxEventTypes = 100
+
+// extractEventRange reads query parameters direction (forward or backward), after-revision, to-revision, limit
+// and event-types.
+func extractEventRange(r *http.Request) (repo.EventRange, error) {
+	var eventRange repo.EventRange
+
+	switch direction := r.URL.Query().Get("direction"); direction {
+	case "", "forward":
+	case "backward":
+		eventRange.Backward = true
+	default:
+		err := fmt.Errorf("invalid direction value [%s]", direction)
+		validationErrors := eserror.NewSimpleValidationError("direction", "oneof=forward backward")
+		return repo.EventRange{}, eserror.NewValidationError(err, validationErrors)
+	}
+
+	var err error
+	eventRange.AfterRevision, err = extractNonNegativeParam(r, "after-revision")
+	if err != nil {
+		return repo.EventRange{}, err
+	}
+
+	eventRange.ToRevision, err = extractNonNegativeParam(r, "to-revision")
+	if err != nil {
+		return repo.EventRange{}, err
+	}
+
+	eventRange.Limit, err = extractLimitParam(r)
+	if err != nil {
+		return repo.EventRange{}, err
+	}
+
+	eventRange.EventTypes, err = extractEventTypes(r)
+	if err != nil {
+		return repo.EventRange{}, err
+	}
+
+	return eventRange, nil
+}
+
+// extractEventTypes reads the event-types query parameter, a comma-separated list which may also be repeated.
+func extractEventTypes(r *http.Request) ([]string, error) {
+	var eventTypes []string
+	for _, value := range r.URL.Query()["event-types"] {
+		for _, eventType := range strings.Split(value, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if eventType != "" && !slices.Contains(eventTypes, eventType) {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+	}
+
+	if len(eventTypes) > maxEventTypes {
+		err := fmt.Errorf("too many event types [%d]", len(eventTypes))
+		validationErrors := eserror.NewSimpleValidationError("event-types", fmt.Sprintf("max=%d", maxEventTypes))
+		return nil, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return eventTypes, nil
+}
+
+func extractNonNegativeParam(r *http.Request, name string) (int, error) {
+	valueStr := r.URL.Query().Get(name)
+	if valueStr == "" {
 		return 0, nil
 	}
 
-	afterRevision, err := strconv.Atoi(afterRevisionStr)
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		err = fmt.Errorf("invalid %s value [%s]", name, valueStr)
+		validationErrors := eserror.NewSimpleValidationError(name, "min=0")
+		return 0, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return value, nil
+}
+
+// extractLimitParam reads the limit query parameter, at most repo.MaxLimit.
+func extractLimitParam(r *http.Request) (int, error) {
+	limit, err := extractNonNegativeParam(r, "limit")
 	if err != nil {
-		return 0, fmt.Errorf("invalid after-revision value: %w", err)
+		return 0, err
+	}
+
+	if limit > repo.MaxLimit {
+		err = fmt.Errorf("invalid limit value [%d]", limit)
+		validationErrors := eserror.NewSimpleValidationError("limit", fmt.Sprintf("max=%d", repo.MaxLimit))
+		return 0, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return limit, nil
+}
+
+// requestedFrom is the lowest revision of the requested range, before the stream bounds apply.
+func requestedFrom(eventRange repo.EventRange) int {
+	if eventRange.Backward {
+		return max(eventRange.ToRevision, 1)
 	}
 
-	return afterRevision, nil
+	return eventRange.AfterRevision + 1
 }
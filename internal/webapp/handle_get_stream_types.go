@@ -0,0 +1,57 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type getStreamTypesResponse struct {
+	StreamTypes []estypes.StreamTypeDefinition `json:"streamTypes"`
+}
+
+type getStreamTypeResponse struct {
+	StreamType estypes.StreamTypeDefinition `json:"streamType"`
+}
+
+// HandleGetStreamTypes lists the stream types of the registry, the list is empty when no registry is configured.
+func (a *WebApp) HandleGetStreamTypes(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamTypes := []estypes.StreamTypeDefinition{}
+	if a.registry != nil {
+		streamTypes = a.registry.StreamTypes()
+	}
+
+	responseBody := getStreamTypesResponse{
+		StreamTypes: streamTypes,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
+
+func (a *WebApp) HandleGetStreamType(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	var definition estypes.StreamTypeDefinition
+	found := false
+	if a.registry != nil {
+		definition, found = a.registry.StreamType(streamType)
+	}
+	if !found {
+		err = fmt.Errorf("stream type [%s] is not registered", streamType)
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	responseBody := getStreamTypeResponse{
+		StreamType: definition,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
@@ -24,7 +24,8 @@ func (a *WebApp) HandleGetStreams(ctx context.Context, r *http.Request) (resp.Es
 		return resp.EsResponse{}, err
 	}
 
-	nextPageKey, err := extractStreamNextPageKey(r)
+	pageKeyScope := "streams/" + streamType
+	nextPageKey, err := a.pageKeys.open(pageKeyScope, r)
 	if err != nil {
 		return resp.EsResponse{}, err
 	}
@@ -33,6 +34,7 @@ func (a *WebApp) HandleGetStreams(ctx context.Context, r *http.Request) (resp.Es
 	if err != nil {
 		return resp.EsResponse{}, fmt.Errorf("failed to get streams: %w", err)
 	}
+	streamPage.NextPageKey = a.pageKeys.seal(pageKeyScope, streamPage.NextPageKey)
 
 	responseBody := getStreamsResponse{
 		StreamPage: streamPage,
@@ -57,9 +59,3 @@ func extractUpdatedAfter(r *http.Request) (time.Time, error) {
 
 	return updatedAfter, nil
 }
-
-func extractStreamNextPageKey(r *http.Request) (string, error) {
-	nextPageKey := r.URL.Query().Get("stream-next-page-key")
-
-	return nextPageKey, nil
-}
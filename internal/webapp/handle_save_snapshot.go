@@ -0,0 +1,78 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+	"time"
+)
+
+type saveSnapshotRequest struct {
+	Snapshot *estypes.NewEsSnapshot `json:"snapshot,omitempty" validate:"required"`
+}
+
+type snapshotResponse struct {
+	Snapshot estypes.Snapshot `json:"snapshot"`
+}
+
+func (a *WebApp) HandleSaveSnapshot(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	var reqBody saveSnapshotRequest
+	err = ExtractRequestBody(r, &reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = esvalidate.Validate(&reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	if reqBody.Snapshot.Revision > stream.Revision {
+		err = fmt.Errorf("snapshot revision [%d] is beyond stream revision [%d]", reqBody.Snapshot.Revision, stream.Revision)
+		validationErrors := eserror.NewSimpleValidationError("snapshot.revision", "lte")
+		return resp.EsResponse{}, eserror.NewValidationError(err, validationErrors)
+	}
+
+	snapshot := estypes.NewSnapshot(streamId, *reqBody.Snapshot, time.Now())
+
+	err = a.esRepo.SaveSnapshot(ctx, snapshot)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	responseBody := snapshotResponse{
+		Snapshot: snapshot,
+	}
+	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody))
+
+	return response, nil
+}
@@ -0,0 +1,50 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type shredPayloadsResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+// HandleShredPayloads makes the payloads of the stream unreadable (crypto-shredding).
+// The events stay in the stream and are returned marked as shredded.
+func (a *WebApp) HandleShredPayloads(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = a.esRepo.ShredPayloads(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to shred payloads: %w", err)
+	}
+
+	responseBody := shredPayloadsResponse{
+		Stream: stream,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
@@ -0,0 +1,105 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type setStreamMetadataRequest struct {
+	Metadata *estypes.StreamMetadata `json:"metadata,omitempty" validate:"required"`
+}
+
+type streamMetadataResponse struct {
+	Metadata estypes.StreamMetadata `json:"metadata"`
+}
+
+func (a *WebApp) HandleGetStreamMetadata(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	var responseBody streamMetadataResponse
+	if stream.Metadata != nil {
+		responseBody.Metadata = *stream.Metadata
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
+
+// HandleSetStreamMetadata replaces the metadata of the stream. It does not change the stream revision.
+func (a *WebApp) HandleSetStreamMetadata(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	var reqBody setStreamMetadataRequest
+	err = ExtractRequestBody(r, &reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = esvalidate.Validate(&reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	stream, err = a.esRepo.SetStreamMetadata(ctx, streamId, *reqBody.Metadata)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to set stream metadata: %w", err)
+	}
+
+	responseBody := streamMetadataResponse{
+		Metadata: *stream.Metadata,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
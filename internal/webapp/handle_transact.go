@@ -0,0 +1,156 @@
+package webapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/esvalidate"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+)
+
+type transactionRequest struct {
+	Streams []estypes.StreamAppend `json:"streams" validate:"required,min=1,dive"`
+}
+
+type transactionResponse struct {
+	Streams []estypes.Stream `json:"streams"`
+}
+
+// HandleTransact appends events to several streams at their expected revisions, all or nothing.
+// When any stream is missing or is not at its expected revision, nothing is written and the missing
+// or conflicting streams are listed in the details of the error.
+// A retry with the same idempotency key returns the result of the completed transaction.
+func (a *WebApp) HandleTransact(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	idempotency, err := ExtractIdempotency(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	var reqBody transactionRequest
+	err = ExtractRequestBody(r, &reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = esvalidate.Validate(&reqBody)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	err = validateTransaction(reqBody.Streams)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	for i, streamAppend := range reqBody.Streams {
+		err = a.validateNewEvents(streamAppend.StreamType, fmt.Sprintf("streams[%d].events", i), streamAppend.Events, true)
+		if err != nil {
+			return resp.EsResponse{}, err
+		}
+	}
+
+	streams, replayed, err := a.transactIdempotently(ctx, idempotency, func(idempotency *repo.Idempotency) ([]estypes.Stream, error) {
+		return a.appendToStreams(ctx, reqBody.Streams, idempotency)
+	})
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to append events to streams: %w", err)
+	}
+
+	responseBody := transactionResponse{
+		Streams: streams,
+	}
+	response := resp.New(resp.WithStatus(http.StatusCreated), resp.WithJson(responseBody), withReplayed(replayed))
+
+	return response, nil
+}
+
+// appendToStreams checks that every stream exists at its expected revision and appends the events to all of them.
+func (a *WebApp) appendToStreams(ctx context.Context, streamAppends []estypes.StreamAppend, idempotency *repo.Idempotency) ([]estypes.Stream, error) {
+	appends := make([]repo.StreamAppend, 0, len(streamAppends))
+	var missing []eserror.StreamConflict
+	var conflicts []eserror.StreamConflict
+	for _, streamAppend := range streamAppends {
+		streamRef := eserror.StreamConflict{StreamType: streamAppend.StreamType, StreamId: streamAppend.StreamId}
+
+		stream, err := a.esRepo.GetStream(ctx, streamAppend.StreamId)
+
+		notFound := &eserror.NotFoundError{}
+		if errors.As(err, &notFound) {
+			missing = append(missing, streamRef)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stream from event store: %w", err)
+		}
+
+		err = stream.ShouldHaveType(streamAppend.StreamType)
+		if err != nil {
+			missing = append(missing, streamRef)
+			continue
+		}
+
+		err = stream.ShouldNotBeDeleted()
+		if err != nil {
+			return nil, eserror.NewGoneError(err)
+		}
+
+		repoAppend := repo.StreamAppend{
+			StreamType: streamAppend.StreamType,
+			StreamId:   streamAppend.StreamId,
+			Revision:   streamAppend.ExpectedRevision + 1,
+			Events:     streamAppend.Events,
+			EventTtl:   stream.Metadata.EventTtl(),
+		}
+		if stream.Revision != streamAppend.ExpectedRevision {
+			conflicts = append(conflicts, streamRef)
+		}
+
+		appends = append(appends, repoAppend)
+	}
+
+	if len(missing) > 0 {
+		err := fmt.Errorf("%d streams not found", len(missing))
+		return nil, eserror.NewStreamNotFoundError(err, missing...)
+	}
+
+	if len(conflicts) > 0 {
+		err := fmt.Errorf("%d streams are not at the expected revisions", len(conflicts))
+		return nil, eserror.NewStreamConflictError(err, conflicts...)
+	}
+
+	return a.esRepo.AppendToStreams(ctx, appends, idempotency)
+}
+
+// validateTransaction checks that every stream is appended to once
+// and that the transaction fits into estypes.MaxTransactionItems.
+func validateTransaction(streamAppends []estypes.StreamAppend) error {
+	items := 0
+	streamIds := make(map[uuid.UUID]bool, len(streamAppends))
+	validationErrors := eserror.NewEmptyValidationErrors()
+
+	for i, streamAppend := range streamAppends {
+		if streamIds[streamAppend.StreamId] {
+			key := fmt.Sprintf("streams[%d].streamId", i)
+			validationErrors.Messages[key] = append(validationErrors.Messages[key], "unique")
+		}
+		streamIds[streamAppend.StreamId] = true
+		items += 1 + len(streamAppend.Events)
+	}
+
+	if items > estypes.MaxTransactionItems {
+		validationErrors.Messages["streams"] = append(validationErrors.Messages["streams"], fmt.Sprintf("max %d streams and events", estypes.MaxTransactionItems))
+	}
+
+	if len(validationErrors.Messages) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("invalid transaction of %d streams and %d items", len(streamAppends), items)
+
+	return eserror.NewValidationError(err, validationErrors)
+}
@@ -0,0 +1,71 @@
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"net/http"
+	"strconv"
+)
+
+type truncateStreamResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+// HandleTruncateStream deletes the events before the given revision. The stream revision stays the same.
+func (a *WebApp) HandleTruncateStream(ctx context.Context, r *http.Request) (resp.EsResponse, error) {
+	streamType, err := ExtractStreamType(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	streamId, err := ExtractStreamId(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	beforeRevision, err := extractBeforeRevision(r)
+	if err != nil {
+		return resp.EsResponse{}, err
+	}
+
+	stream, err := a.esRepo.GetStream(ctx, streamId)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to get stream from event store: %w", err)
+	}
+
+	err = stream.ShouldHaveType(streamType)
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewNotFoundError(err)
+	}
+
+	err = stream.ShouldNotBeDeleted()
+	if err != nil {
+		return resp.EsResponse{}, eserror.NewGoneError(err)
+	}
+
+	stream, err = a.esRepo.TruncateStream(ctx, streamId, beforeRevision)
+	if err != nil {
+		return resp.EsResponse{}, fmt.Errorf("failed to truncate stream: %w", err)
+	}
+
+	responseBody := truncateStreamResponse{
+		Stream: stream,
+	}
+	response := resp.New(resp.WithStatus(http.StatusOK), resp.WithJson(responseBody))
+
+	return response, nil
+}
+
+func extractBeforeRevision(r *http.Request) (int, error) {
+	beforeRevision, err := strconv.Atoi(r.URL.Query().Get("before-revision"))
+	if err != nil || beforeRevision < 2 {
+		err = fmt.Errorf("invalid before-revision value [%s]", r.URL.Query().Get("before-revision"))
+		validationErrors := eserror.NewSimpleValidationError("before-revision", "min=2")
+		return 0, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return beforeRevision, nil
+}
@@ -0,0 +1,130 @@
+package webapp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/resp"
+	"io"
+	"net/http"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// ExtractIdempotency reads the Idempotency-Key header and fingerprints the request.
+// It returns nil when the header is not set.
+//
+// The request body is read and restored, so it still can be extracted afterward.
+func ExtractIdempotency(r *http.Request) (*repo.Idempotency, error) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("idempotency key is too long: %d", len(key))
+		validationErrors := eserror.NewSimpleValidationError(IdempotencyKeyHeader, "max")
+		return nil, eserror.NewValidationError(err, validationErrors)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		err = fmt.Errorf("failed to read request body: %w", err)
+		validationErrors := eserror.NewSimpleValidationError("requestBody", "failed to read request body")
+		return nil, eserror.NewValidationError(err, validationErrors)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + "\n" + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	idempotency := &repo.Idempotency{
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	return idempotency, nil
+}
+
+// writeIdempotently performs the write, unless the request is a replay of an earlier one with the same idempotency key.
+// For a replay the result of the earlier write is returned, and the second return value is true.
+// The write gets the idempotency to remember its result with (see repo.WriteOptions).
+func (a *WebApp) writeIdempotently(ctx context.Context, idempotency *repo.Idempotency, write func(*repo.Idempotency) (estypes.Stream, error)) (estypes.Stream, bool, error) {
+	return writeOrReplay(ctx, a.esRepo, idempotency, write, func(result repo.IdempotentResult) estypes.Stream {
+		return result.Stream
+	})
+}
+
+// transactIdempotently is writeIdempotently of a multi-stream write.
+func (a *WebApp) transactIdempotently(ctx context.Context, idempotency *repo.Idempotency, write func(*repo.Idempotency) ([]estypes.Stream, error)) ([]estypes.Stream, bool, error) {
+	return writeOrReplay(ctx, a.esRepo, idempotency, write, func(result repo.IdempotentResult) []estypes.Stream {
+		return result.Streams
+	})
+}
+
+func writeOrReplay[T any](ctx context.Context, esRepo repo.EsStore, idempotency *repo.Idempotency, write func(*repo.Idempotency) (T, error), replay func(repo.IdempotentResult) T) (T, bool, error) {
+	var zero T
+	if idempotency == nil {
+		written, err := write(nil)
+		return written, false, err
+	}
+
+	result, found, err := findIdempotentResult(ctx, esRepo, *idempotency)
+	if err != nil {
+		return zero, false, err
+	}
+	if found {
+		return replay(result), true, nil
+	}
+
+	written, err := write(idempotency)
+
+	dataConflictErr := &eserror.DataConflictError{}
+	if errors.As(err, &dataConflictErr) {
+		// the same request may have been completed concurrently
+		result, found, findErr := findIdempotentResult(ctx, esRepo, *idempotency)
+		if findErr == nil && found {
+			return replay(result), true, nil
+		}
+	}
+
+	return written, false, err
+}
+
+func findIdempotentResult(ctx context.Context, esRepo repo.EsStore, idempotency repo.Idempotency) (repo.IdempotentResult, bool, error) {
+	result, err := esRepo.GetIdempotentResult(ctx, idempotency.Key)
+
+	notFoundErr := &eserror.NotFoundError{}
+	if errors.As(err, &notFoundErr) {
+		return repo.IdempotentResult{}, false, nil
+	}
+	if err != nil {
+		return repo.IdempotentResult{}, false, fmt.Errorf("failed to get idempotent result: %w", err)
+	}
+
+	if result.RequestHash != idempotency.RequestHash {
+		err = fmt.Errorf("idempotency key [%s] is already used for a different request", idempotency.Key)
+		validationErrors := eserror.NewSimpleValidationError(IdempotencyKeyHeader, "already used for a different request")
+		return repo.IdempotentResult{}, false, eserror.NewValidationError(err, validationErrors)
+	}
+
+	return result, true, nil
+}
+
+func withReplayed(replayed bool) func(r *resp.EsResponse) {
+	if !replayed {
+		return func(r *resp.EsResponse) {}
+	}
+
+	return resp.WithHeader(IdempotentReplayedHeader, "true")
+}
@@ -0,0 +1,76 @@
+package webapp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"net/http"
+)
+
+// pageKeys turns next page keys of the storage into opaque tokens for clients and back.
+//
+// A token is the base64url-encoded storage key followed by its HMAC-SHA256. The MAC also covers
+// the scope of the listing (e.g. the stream type), so that a token is only accepted where it was issued.
+type pageKeys struct {
+	secret []byte
+}
+
+func newRandomPageKeys() pageKeys {
+	secret := make([]byte, sha256.Size)
+	_, _ = rand.Read(secret)
+
+	return pageKeys{secret: secret}
+}
+
+func (k pageKeys) seal(scope string, pageKey *string) *string {
+	if pageKey == nil {
+		return nil
+	}
+
+	data := []byte(*pageKey)
+	token := base64.RawURLEncoding.EncodeToString(append(data, k.mac(scope, data)...))
+
+	return &token
+}
+
+// open reads the storage key from the next-page-key query parameter, an empty key means the first page.
+// A token that was not issued for the scope is rejected with eserror.ValidationError.
+func (k pageKeys) open(scope string, r *http.Request) (string, error) {
+	token := r.URL.Query().Get(estypes.NextPageKeyParam)
+	if token == "" {
+		return "", nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", invalidPageKey(err)
+	}
+	if len(sealed) < sha256.Size {
+		return "", invalidPageKey(errors.New("next page key is too short"))
+	}
+
+	data, mac := sealed[:len(sealed)-sha256.Size], sealed[len(sealed)-sha256.Size:]
+	if !hmac.Equal(mac, k.mac(scope, data)) {
+		return "", invalidPageKey(errors.New("next page key signature mismatch"))
+	}
+
+	return string(data), nil
+}
+
+func (k pageKeys) mac(scope string, data []byte) []byte {
+	h := hmac.New(sha256.New, k.secret)
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+func invalidPageKey(err error) error {
+	validationErrors := eserror.NewSimpleValidationError(estypes.NextPageKeyParam, "invalid next page key")
+	return eserror.NewValidationError(err, validationErrors)
+}
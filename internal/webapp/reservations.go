@@ -0,0 +1,51 @@
+package webapp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+)
+
+// validateReservations checks that every key is claimed or released once,
+// and that the reservations fit into the transaction of the write together with the events.
+func validateReservations(claim []estypes.ReservationKey, release []estypes.ReservationKey, eventCount int) error {
+	validationErrors := eserror.NewEmptyValidationErrors()
+
+	seen := make(map[estypes.ReservationKey]bool, len(claim)+len(release))
+	checkUnique := func(field string, keys []estypes.ReservationKey) {
+		for i, key := range keys {
+			if seen[key] {
+				name := fmt.Sprintf("%s[%d]", field, i)
+				validationErrors.Messages[name] = append(validationErrors.Messages[name], "unique")
+			}
+			seen[key] = true
+		}
+	}
+	checkUnique("claim", claim)
+	checkUnique("release", release)
+
+	reservationCount := len(claim) + len(release)
+	if reservationCount > estypes.MaxReservationsPerWrite {
+		message := fmt.Sprintf("max %d claimed and released keys", estypes.MaxReservationsPerWrite)
+		validationErrors.Messages["claim"] = append(validationErrors.Messages["claim"], message)
+	} else if reservationCount+eventCount > estypes.MaxEventsPerAppend {
+		message := fmt.Sprintf("max %d events and reservations", estypes.MaxEventsPerAppend)
+		validationErrors.Messages["events"] = append(validationErrors.Messages["events"], message)
+	}
+
+	if len(validationErrors.Messages) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("invalid reservations: %d claimed, %d released", len(claim), len(release))
+
+	return eserror.NewValidationError(err, validationErrors)
+}
+
+// isReservationConflict tells a conflict on the unique keys from a conflict on the stream revision.
+func isReservationConflict(err error) bool {
+	conflict := &eserror.DataConflictError{}
+
+	return errors.As(err, &conflict) && conflict.Details != nil && len(conflict.Details.Reservations) > 0
+}
@@ -29,18 +29,32 @@ func New(requestId uuid.UUID, err error) *WebError {
 
 	dataConflictErr := &eserror.DataConflictError{}
 	notFoundErr := &eserror.NotFoundError{}
+	goneErr := &eserror.GoneError{}
 	invalid := &eserror.ValidationError{}
+	unavailableErr := &eserror.UnavailableError{}
 
 	if errors.As(err, &dataConflictErr) {
 		webErr.Status = http.StatusConflict
 		webErr.MessageForClient = "Trying to write based on invalid state. Refetch and try again."
+		if dataConflictErr.Details != nil {
+			webErr.Details = dataConflictErr.Details
+		}
 	} else if errors.As(err, &notFoundErr) {
 		webErr.Status = http.StatusNotFound
 		webErr.MessageForClient = "Requested resource not found"
+		if notFoundErr.Details != nil {
+			webErr.Details = notFoundErr.Details
+		}
+	} else if errors.As(err, &goneErr) {
+		webErr.Status = http.StatusGone
+		webErr.MessageForClient = "Requested resource has been deleted"
 	} else if errors.As(err, &invalid) {
 		webErr.Status = http.StatusBadRequest
 		webErr.MessageForLog = "Bad request"
 		webErr.Details = invalid.ValidationErrors
+	} else if errors.As(err, &unavailableErr) {
+		webErr.Status = http.StatusServiceUnavailable
+		webErr.MessageForClient = "Too many concurrent writes. Try again later."
 	}
 
 	return webErr
@@ -54,6 +68,13 @@ func (e *WebError) Unwrap() error {
 	return e.Err
 }
 
+// retryAfterSeconds is the Retry-After of a 503 response, contention on writes usually clears quickly.
+const retryAfterSeconds = "1"
+
 func IntoResponse(err WebError) resp.EsResponse {
+	if err.Status == http.StatusServiceUnavailable {
+		return resp.New(resp.WithStatus(err.Status), resp.WithHeader("Retry-After", retryAfterSeconds), resp.WithJson(err))
+	}
+
 	return resp.New(resp.WithStatus(err.Status), resp.WithJson(err))
 }
@@ -0,0 +1,44 @@
+package webapp
+
+import (
+	"fmt"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+)
+
+// validateNewEvents checks the new events against the registry, when it is configured,
+// and sets the current schema version of their event types.
+// A single event is named after its field in the request body, a batch is indexed, e.g. "events[1]".
+func (a *WebApp) validateNewEvents(streamType string, field string, newEvents []estypes.NewEsEvent, batch bool) error {
+	if a.registry == nil {
+		return nil
+	}
+
+	events := make(map[string]estypes.NewEsEvent, len(newEvents))
+	for i, newEvent := range newEvents {
+		eventField := field
+		if batch {
+			eventField = fmt.Sprintf("%s[%d]", field, i)
+		}
+		events[eventField] = newEvent
+	}
+
+	err := a.registry.ValidateEvents(streamType, events)
+	if err != nil {
+		return err
+	}
+
+	for i := range newEvents {
+		newEvents[i].SchemaVersion, _ = a.registry.SchemaVersion(streamType, newEvents[i].EventType)
+	}
+
+	return nil
+}
+
+// upcastEvents converts payloads of older schema versions with the upcasters of the registry, when it is configured.
+func (a *WebApp) upcastEvents(streamType string, events []estypes.Event) error {
+	if a.registry == nil {
+		return nil
+	}
+
+	return a.registry.Upcast(streamType, events)
+}
@@ -3,7 +3,9 @@ package webapp
 import (
 	"encoding/json"
 	"errors"
+	"expvar"
 	"github.com/ilia-tolliu/serverless-event-store/internal/logger"
+	"github.com/ilia-tolliu/serverless-event-store/internal/registry"
 	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
 	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types"
 	"github.com/ilia-tolliu/serverless-event-store/internal/webapp/types/middleware"
@@ -14,17 +16,49 @@ import (
 
 type WebApp struct {
 	*http.ServeMux
-	mw     []middleware.EsMiddleware
-	log    *zap.SugaredLogger
-	esRepo *repo.EsRepo
+	mw       []middleware.EsMiddleware
+	log      *zap.SugaredLogger
+	esRepo   repo.EsStore
+	pageKeys pageKeys
+	registry *registry.Registry
 }
 
-func New(esRepo *repo.EsRepo, log *zap.SugaredLogger) *WebApp {
+// WithPageKeySecret sets the secret that signs next page keys.
+// It should be the same for all instances of the Event Store, otherwise a page key is only accepted
+// by the instance that issued it. Without it, a random secret is used.
+func WithPageKeySecret(secret []byte) func(*WebApp) {
+	return func(a *WebApp) {
+		a.pageKeys = pageKeys{secret: secret}
+	}
+}
+
+// WithRegistry makes the Event Store accept only the stream types and event types declared in the registry,
+// with payloads matching their schemas. Without it, any stream type and event type is accepted.
+func WithRegistry(registry *registry.Registry) func(*WebApp) {
+	return func(a *WebApp) {
+		a.registry = registry
+	}
+}
+
+// WithDebugVars serves the expvar metrics at /debug/vars, including the command line and memory statistics.
+// It is meant for local runs only and should not be exposed on a public URL.
+func WithDebugVars() func(*WebApp) {
+	return func(a *WebApp) {
+		a.Handle("GET /debug/vars", expvar.Handler())
+	}
+}
+
+func New(esRepo repo.EsStore, log *zap.SugaredLogger, options ...func(*WebApp)) *WebApp {
 	webApp := &WebApp{
 		ServeMux: http.NewServeMux(),
 		mw:       []middleware.EsMiddleware{},
 		log:      log,
 		esRepo:   esRepo,
+		pageKeys: newRandomPageKeys(),
+	}
+
+	for _, option := range options {
+		option(webApp)
 	}
 
 	webApp.mw = append(webApp.mw, MwLogRequest)
@@ -32,12 +66,30 @@ func New(esRepo *repo.EsRepo, log *zap.SugaredLogger) *WebApp {
 	webApp.esHandle("GET /liveness-check", webApp.HandleLivenessCheck)
 	webApp.esHandle("POST /streams/{streamType}", webApp.HandleCreateStream)
 	webApp.esHandle("GET /streams/{streamType}", webApp.HandleGetStreams)
+	webApp.esHandle("PUT /streams/{streamType}/{streamId}", webApp.HandleCreateStreamWithId)
+	webApp.esHandle("DELETE /streams/{streamType}/{streamId}", webApp.HandleDeleteStream)
 	webApp.esHandle("GET /streams/{streamType}/{streamId}/details", webApp.HandleGetStreamDetails)
+	webApp.esHandle("GET /streams/{streamType}/{streamId}/metadata", webApp.HandleGetStreamMetadata)
+	webApp.esHandle("PUT /streams/{streamType}/{streamId}/metadata", webApp.HandleSetStreamMetadata)
 	webApp.esHandle("PUT /streams/{streamType}/{streamId}/events/{streamRevision}", webApp.HandleAppendEvent)
+	webApp.esHandle("GET /streams/{streamType}/{streamId}/events/{streamRevision}", webApp.HandleGetStreamEvent)
+	webApp.esHandle("POST /streams/{streamType}/{streamId}/events", webApp.HandleAppendToStream)
 	webApp.esHandle("GET /streams/{streamType}/{streamId}/events", webApp.HandleGetStreamEvents)
+	webApp.esHandle("DELETE /streams/{streamType}/{streamId}/events", webApp.HandleTruncateStream)
+	webApp.esHandle("DELETE /streams/{streamType}/{streamId}/payloads", webApp.HandleShredPayloads)
+	webApp.esHandle("PUT /streams/{streamType}/{streamId}/snapshot", webApp.HandleSaveSnapshot)
+	webApp.esHandle("GET /streams/{streamType}/{streamId}/snapshot", webApp.HandleGetSnapshot)
+	webApp.esHandle("POST /transactions", webApp.HandleTransact)
+	webApp.esHandle("GET /reservations/{namespace}/{key}", webApp.HandleGetReservation)
+	webApp.esHandle("GET /all/events", webApp.HandleGetAllEvents)
+	webApp.esHandle("GET /categories/{streamType}/events", webApp.HandleGetCategoryEvents)
+	webApp.esHandle("GET /stream-types", webApp.HandleGetStreamTypes)
+	webApp.esHandle("GET /stream-types/{streamType}", webApp.HandleGetStreamType)
 
 	webApp.HandleFunc("/openapi/openapi-spec.json", HandleOpenapiSpec)
 	webApp.HandleFunc("/openapi/", HandleSwaggerUi)
+	// debug endpoints are not found unless enabled with WithDebugVars, rather than redirected to the specification
+	webApp.Handle("/debug/", http.NotFoundHandler())
 	webApp.Handle("/", http.RedirectHandler("/openapi/", http.StatusMovedPermanently))
 
 	return webApp
@@ -0,0 +1,1902 @@
+package webapp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilia-tolliu/serverless-event-store/eshttp"
+	"github.com/ilia-tolliu/serverless-event-store/estypes"
+	"github.com/ilia-tolliu/serverless-event-store/internal/blobstore"
+	"github.com/ilia-tolliu/serverless-event-store/internal/eserror"
+	"github.com/ilia-tolliu/serverless-event-store/internal/keyprovider"
+	"github.com/ilia-tolliu/serverless-event-store/internal/registry"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo/boltrepo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/repo/memrepo"
+	"github.com/ilia-tolliu/serverless-event-store/internal/webapp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// forEachStore runs the test against every storage backend that does not need AWS.
+func forEachStore(t *testing.T, test func(t *testing.T, webApp *webapp.WebApp)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, webapp.New(memrepo.NewMemRepo(), zap.NewNop().Sugar()))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+
+		test(t, webapp.New(boltRepo, zap.NewNop().Sugar()))
+	})
+}
+
+func doRequest(t *testing.T, webApp *webapp.WebApp, method string, path string, body any, target any) int {
+	t.Helper()
+
+	rec := doRequestWithHeader(t, webApp, method, path, http.Header{}, body, target)
+
+	return rec.Code
+}
+
+func doRequestWithHeader(t *testing.T, webApp *webapp.WebApp, method string, path string, header http.Header, body any, target any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+
+	req := httptest.NewRequest(method, path, &reqBody)
+	req.Header = header
+	rec := httptest.NewRecorder()
+	webApp.ServeHTTP(rec, req)
+
+	if target != nil && rec.Code < http.StatusBadRequest {
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(target))
+	}
+
+	return rec
+}
+
+// readEventPage reads a page of events, P is the page type of the path, e.g. estypes.EventPage.
+func readEventPage[P any](t *testing.T, webApp *webapp.WebApp, path string) P {
+	t.Helper()
+
+	var events struct {
+		EventPage P `json:"eventPage"`
+	}
+	status := doRequest(t, webApp, http.MethodGet, path, nil, &events)
+	require.Equal(t, http.StatusOK, status)
+
+	return events.EventPage
+}
+
+type eventRef struct {
+	StreamId uuid.UUID
+	Revision int
+}
+
+func eventRefs(events []estypes.Event) []eventRef {
+	refs := make([]eventRef, 0, len(events))
+	for _, event := range events {
+		refs = append(refs, eventRef{event.StreamId, event.Revision})
+	}
+
+	return refs
+}
+
+func eventRevisions(events []estypes.Event) []int {
+	revisions := make([]int, 0, len(events))
+	for _, event := range events {
+		revisions = append(revisions, event.Revision)
+	}
+
+	return revisions
+}
+
+func eventPositions(events []estypes.Event) []int {
+	positions := make([]int, 0, len(events))
+	for _, event := range events {
+		positions = append(positions, event.Position)
+	}
+
+	return positions
+}
+
+type streamResponse struct {
+	Stream estypes.Stream `json:"stream"`
+}
+
+func createTestStream(t *testing.T, webApp *webapp.WebApp, streamType string) estypes.Stream {
+	t.Helper()
+
+	var created streamResponse
+	status := doRequest(t, webApp, http.MethodPost, "/streams/"+streamType, map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "stream-created", Payload: "payload1"},
+	}, &created)
+	require.Equal(t, http.StatusCreated, status)
+
+	return created.Stream
+}
+
+func TestCreateAndAppend(t *testing.T) {
+	forEachStore(t, testCreateAndAppend)
+}
+
+func testCreateAndAppend(t *testing.T, webApp *webapp.WebApp) {
+	stream := createTestStream(t, webApp, "test-stream")
+	require.Equal(t, 1, stream.Revision)
+
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	var appended streamResponse
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+	}, &appended)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 2, appended.Stream.Revision)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload3"},
+	}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/4", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload3"},
+	}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 2)
+	require.Equal(t, "payload1", events.EventPage.Events[0].Payload)
+	require.Equal(t, "payload2", events.EventPage.Events[1].Payload)
+	require.Equal(t, 2, events.EventPage.LastEvaluatedRevision)
+	require.False(t, events.EventPage.HasMore)
+
+	var details streamResponse
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/details", nil, &details)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 2, details.Stream.Revision)
+
+	status = doRequest(t, webApp, http.MethodGet, "/streams/other-stream/"+stream.StreamId.String()+"/details", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+}
+
+func TestGetStreams(t *testing.T) {
+	forEachStore(t, testGetStreams)
+}
+
+func testGetStreams(t *testing.T, webApp *webapp.WebApp) {
+	first := createTestStream(t, webApp, "test-stream")
+	second := createTestStream(t, webApp, "test-stream")
+	createTestStream(t, webApp, "other-stream")
+
+	var streams struct {
+		StreamPage estypes.StreamPage `json:"streamPage"`
+	}
+	status := doRequest(t, webApp, http.MethodGet, "/streams/test-stream", nil, &streams)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, streams.StreamPage.Streams, 2)
+	require.Equal(t, first.StreamId, streams.StreamPage.Streams[0].StreamId)
+	require.Equal(t, second.StreamId, streams.StreamPage.Streams[1].StreamId)
+	require.False(t, streams.StreamPage.HasMore)
+}
+
+func TestAppendEvents(t *testing.T) {
+	forEachStore(t, testAppendEvents)
+}
+
+func testAppendEvents(t *testing.T, webApp *webapp.WebApp) {
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	var appended streamResponse
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"events": []estypes.NewEsEvent{
+			{EventType: "first-happened", Payload: "payload2"},
+			{EventType: "second-happened", Payload: "payload3"},
+			{EventType: "third-happened", Payload: "payload4"},
+		},
+	}, &appended)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 4, appended.Stream.Revision)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/4", map[string]any{
+		"events": []estypes.NewEsEvent{
+			{EventType: "conflicting", Payload: "payload"},
+			{EventType: "conflicting", Payload: "payload"},
+		},
+	}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/5", map[string]any{
+		"event":  estypes.NewEsEvent{EventType: "ambiguous", Payload: "payload"},
+		"events": []estypes.NewEsEvent{{EventType: "ambiguous", Payload: "payload"}},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	tooMany := make([]estypes.NewEsEvent, estypes.MaxEventsPerAppend+1)
+	for i := range tooMany {
+		tooMany[i] = estypes.NewEsEvent{EventType: "too-many", Payload: "payload"}
+	}
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/5", map[string]any{"events": tooMany}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events?after-revision=1", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 3)
+	for i, event := range events.EventPage.Events {
+		require.Equal(t, i+2, event.Revision)
+	}
+	require.Equal(t, "third-happened", events.EventPage.Events[2].EventType)
+}
+
+func TestIdempotentWrites(t *testing.T) {
+	forEachStore(t, testIdempotentWrites)
+}
+
+func testIdempotentWrites(t *testing.T, webApp *webapp.WebApp) {
+	createHeader := http.Header{webapp.IdempotencyKeyHeader: {"create-key"}}
+	createBody := map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "stream-created", Payload: "payload1"},
+	}
+
+	var created, createReplayed streamResponse
+	rec := doRequestWithHeader(t, webApp, http.MethodPost, "/streams/test-stream", createHeader, createBody, &created)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Empty(t, rec.Header().Get(webapp.IdempotentReplayedHeader))
+
+	rec = doRequestWithHeader(t, webApp, http.MethodPost, "/streams/test-stream", createHeader, createBody, &createReplayed)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(webapp.IdempotentReplayedHeader))
+	require.Equal(t, created.Stream.StreamId, createReplayed.Stream.StreamId)
+
+	streamPath := "/streams/test-stream/" + created.Stream.StreamId.String()
+	appendHeader := http.Header{webapp.IdempotencyKeyHeader: {"append-key"}}
+	appendBody := map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+	}
+
+	var appended, appendReplayed streamResponse
+	rec = doRequestWithHeader(t, webApp, http.MethodPut, streamPath+"/events/2", appendHeader, appendBody, &appended)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = doRequestWithHeader(t, webApp, http.MethodPut, streamPath+"/events/2", appendHeader, appendBody, &appendReplayed)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(webapp.IdempotentReplayedHeader))
+	require.Equal(t, appended.Stream, appendReplayed.Stream)
+
+	rec = doRequestWithHeader(t, webApp, http.MethodPut, streamPath+"/events/3", appendHeader, appendBody, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var details streamResponse
+	status := doRequest(t, webApp, http.MethodGet, streamPath+"/details", nil, &details)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 2, details.Stream.Revision)
+
+	var streams struct {
+		StreamPage estypes.StreamPage `json:"streamPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/streams/test-stream", nil, &streams)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, streams.StreamPage.Streams, 1)
+}
+
+func TestCreateStreamWithId(t *testing.T) {
+	forEachStore(t, testCreateStreamWithId)
+}
+
+func testCreateStreamWithId(t *testing.T, webApp *webapp.WebApp) {
+	streamId := uuid.NewSHA1(uuid.NameSpaceURL, []byte("order-123"))
+	streamPath := "/streams/test-stream/" + streamId.String()
+	body := map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "stream-created", Payload: "payload1"},
+	}
+
+	var created streamResponse
+	status := doRequest(t, webApp, http.MethodPut, streamPath, body, &created)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, streamId, created.Stream.StreamId)
+	require.Equal(t, 1, created.Stream.Revision)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath, body, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPut, "/streams/test-stream/not-a-uuid", body, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestSnapshots(t *testing.T) {
+	forEachStore(t, testSnapshots)
+}
+
+func testSnapshots(t *testing.T, webApp *webapp.WebApp) {
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	status := doRequest(t, webApp, http.MethodGet, streamPath+"/snapshot", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/snapshot", map[string]any{
+		"snapshot": estypes.NewEsSnapshot{Revision: 3, SchemaVersion: 1, State: "state3"},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/snapshot", map[string]any{
+		"snapshot": estypes.NewEsSnapshot{Revision: 2, SchemaVersion: 1, State: "state2"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/snapshot", map[string]any{
+		"snapshot": estypes.NewEsSnapshot{Revision: 1, SchemaVersion: 1, State: "state1"},
+	}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	var snapshot struct {
+		Snapshot estypes.Snapshot `json:"snapshot"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/snapshot", nil, &snapshot)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 2, snapshot.Snapshot.Revision)
+	require.Equal(t, "state2", snapshot.Snapshot.State)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 2)
+}
+
+func TestEventMetadata(t *testing.T) {
+	forEachStore(t, testEventMetadata)
+}
+
+func testEventMetadata(t *testing.T, webApp *webapp.WebApp) {
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	metadata := estypes.EventMetadata{
+		CorrelationId: "order-checkout-1",
+		CausationId:   "command-1",
+		Actor:         "billing-service",
+		Headers:       map[string]string{"tenant": "acme"},
+	}
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2", Metadata: metadata},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 2)
+	require.Equal(t, estypes.EventMetadata{}, events.EventPage.Events[0].Metadata)
+	require.Equal(t, metadata, events.EventPage.Events[1].Metadata)
+}
+
+func TestJsonPayloads(t *testing.T) {
+	forEachStore(t, testJsonPayloads)
+}
+
+func testJsonPayloads(t *testing.T, webApp *webapp.WebApp) {
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	jsonEvent, err := estypes.NewJsonEvent("something-happened", map[string]any{"name": "test name", "count": 2})
+	require.NoError(t, err)
+
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"events": []any{
+			jsonEvent,
+			map[string]any{"eventType": "something-happened", "payload": []any{1, "two", nil}},
+			map[string]any{"eventType": "something-happened", "payload": "plain text", "contentType": "text/plain"},
+		},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/5", map[string]any{
+		"event": map[string]any{"eventType": "something-happened", "payload": map[string]any{}, "contentType": "text/plain"},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/5", map[string]any{
+		"event": map[string]any{"eventType": "something-happened", "payload": "not a JSON", "contentType": estypes.ContentTypeJson},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	rec := doRequestWithHeader(t, webApp, http.MethodGet, streamPath+"/events", http.Header{}, nil, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var raw struct {
+		EventPage struct {
+			Events []struct {
+				Payload json.RawMessage `json:"payload"`
+			} `json:"events"`
+		} `json:"eventPage"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &raw))
+	require.Len(t, raw.EventPage.Events, 4)
+	require.JSONEq(t, `"payload1"`, string(raw.EventPage.Events[0].Payload))
+	require.JSONEq(t, `{"name":"test name","count":2}`, string(raw.EventPage.Events[1].Payload))
+	require.JSONEq(t, `[1,"two",null]`, string(raw.EventPage.Events[2].Payload))
+	require.JSONEq(t, `"plain text"`, string(raw.EventPage.Events[3].Payload))
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+	require.Equal(t, "payload1", events.EventPage.Events[0].Payload)
+
+	var decoded struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	jsonPayload := events.EventPage.Events[1]
+	require.Equal(t, estypes.ContentTypeJson, jsonPayload.ContentType)
+	require.NoError(t, jsonPayload.DecodePayload(&decoded))
+	require.Equal(t, "test name", decoded.Name)
+	require.Equal(t, 2, decoded.Count)
+
+	require.Equal(t, "text/plain", events.EventPage.Events[3].ContentType)
+	require.Equal(t, "plain text", events.EventPage.Events[3].Payload)
+}
+
+func TestLargePayloads(t *testing.T) {
+	blobDir := filepath.Join(t.TempDir(), "blobs")
+	fsBlobStore, err := blobstore.NewFsBlobStore(blobDir)
+	require.NoError(t, err)
+
+	boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{Offload: repo.NewPayloadOffload(fsBlobStore, 64)})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+
+	webApp := webapp.New(boltRepo, zap.NewNop().Sugar())
+
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	largeText := strings.Repeat("extracted text ", 100)
+	largeJson, err := estypes.NewJsonEvent("something-happened", map[string]any{"text": largeText})
+	require.NoError(t, err)
+
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"events": []estypes.NewEsEvent{
+			{EventType: "something-happened", Payload: largeText},
+			largeJson,
+		},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	blobs, err := os.ReadDir(filepath.Join(blobDir, stream.StreamId.String()))
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 3)
+	require.Equal(t, "payload1", events.EventPage.Events[0].Payload)
+	require.Equal(t, largeText, events.EventPage.Events[1].Payload)
+	require.JSONEq(t, largeJson.Payload, events.EventPage.Events[2].Payload)
+	require.Equal(t, estypes.ContentTypeJson, events.EventPage.Events[2].ContentType)
+}
+
+func TestCompressedPayloads(t *testing.T) {
+	for _, codec := range []string{repo.CodecGzip, repo.CodecZstd} {
+		t.Run(codec, func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "event-store.db")
+
+			plainRepo, err := boltrepo.NewBoltRepo(dbPath, repo.PayloadStorage{})
+			require.NoError(t, err)
+			stream := createTestStream(t, webapp.New(plainRepo, zap.NewNop().Sugar()), "test-stream")
+			require.NoError(t, plainRepo.Close())
+
+			compression, err := repo.NewPayloadCompression(codec, 16)
+			require.NoError(t, err)
+			compressingRepo, err := boltrepo.NewBoltRepo(dbPath, repo.PayloadStorage{Compression: compression})
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, compressingRepo.Close()) })
+			webApp := webapp.New(compressingRepo, zap.NewNop().Sugar(), webapp.WithDebugVars())
+
+			streamPath := "/streams/test-stream/" + stream.StreamId.String()
+			verbosePayload := strings.Repeat(`{"name":"test name"},`, 100)
+
+			status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+				"event": estypes.NewEsEvent{EventType: "something-happened", Payload: verbosePayload},
+			}, nil)
+			require.Equal(t, http.StatusCreated, status)
+
+			var events struct {
+				EventPage estypes.EventPage `json:"eventPage"`
+			}
+			status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+			require.Equal(t, http.StatusOK, status)
+			require.Len(t, events.EventPage.Events, 2)
+			require.Equal(t, "payload1", events.EventPage.Events[0].Payload)
+			require.Equal(t, verbosePayload, events.EventPage.Events[1].Payload)
+
+			var vars struct {
+				BytesSaved int `json:"payloadCompressionBytesSaved"`
+			}
+			status = doRequest(t, webApp, http.MethodGet, "/debug/vars", nil, &vars)
+			require.Equal(t, http.StatusOK, status)
+			require.Positive(t, vars.BytesSaved)
+		})
+	}
+}
+
+func TestPayloadEncryption(t *testing.T) {
+	blobDir := filepath.Join(t.TempDir(), "blobs")
+	fsBlobStore, err := blobstore.NewFsBlobStore(blobDir)
+	require.NoError(t, err)
+
+	keyDir := filepath.Join(t.TempDir(), "keys")
+	fileKeyProvider, err := keyprovider.NewFileKeyProvider(keyDir)
+	require.NoError(t, err)
+
+	compression, err := repo.NewPayloadCompression(repo.CodecGzip, 16)
+	require.NoError(t, err)
+
+	boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{
+		Offload:     repo.NewPayloadOffload(fsBlobStore, 512),
+		Compression: compression,
+		Encryption:  repo.NewPayloadEncryption(fileKeyProvider),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+
+	webApp := webapp.New(boltRepo, zap.NewNop().Sugar())
+
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	// random text does not compress well, so it gets offloaded
+	largePayload := uuid.NewString()
+	for len(largePayload) < 1024 {
+		largePayload += uuid.NewString()
+	}
+
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: largePayload},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	blobs, err := os.ReadDir(filepath.Join(blobDir, stream.StreamId.String()))
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	blob, err := os.ReadFile(filepath.Join(blobDir, stream.StreamId.String(), blobs[0].Name()))
+	require.NoError(t, err)
+	require.NotContains(t, string(blob), largePayload[:36])
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 2)
+	require.Equal(t, "payload1", events.EventPage.Events[0].Payload)
+	require.Equal(t, largePayload, events.EventPage.Events[1].Payload)
+
+	testShredPayloads(t, webApp)
+}
+
+func TestShredPayloads(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		testShredPayloads(t, webapp.New(memrepo.NewMemRepo(), zap.NewNop().Sugar()))
+	})
+
+	t.Run("file without encryption", func(t *testing.T) {
+		boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+		webApp := webapp.New(boltRepo, zap.NewNop().Sugar())
+
+		stream := createTestStream(t, webApp, "test-stream")
+		status := doRequest(t, webApp, http.MethodDelete, "/streams/test-stream/"+stream.StreamId.String()+"/payloads", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func testShredPayloads(t *testing.T, webApp *webapp.WebApp) {
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/snapshot", map[string]any{
+		"snapshot": estypes.NewEsSnapshot{Revision: 1, State: "state1"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodDelete, "/streams/other-stream/"+stream.StreamId.String()+"/payloads", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	status = doRequest(t, webApp, http.MethodDelete, streamPath+"/payloads", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 1)
+	require.True(t, events.EventPage.Events[0].Shredded)
+	require.Empty(t, events.EventPage.Events[0].Payload)
+	require.Equal(t, "stream-created", events.EventPage.Events[0].EventType)
+
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/snapshot", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 2)
+	require.False(t, events.EventPage.Events[1].Shredded)
+	require.Equal(t, "payload2", events.EventPage.Events[1].Payload)
+}
+
+func TestSoftDeleteStream(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		status := doRequest(t, webApp, http.MethodDelete, streamPath+"?mode=purge", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, "/streams/other-stream/"+stream.StreamId.String(), nil, nil)
+		require.Equal(t, http.StatusNotFound, status)
+
+		var deleted struct {
+			Stream estypes.Stream `json:"stream"`
+		}
+		status = doRequest(t, webApp, http.MethodDelete, streamPath, nil, &deleted)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, estypes.DeletionSoft, deleted.Stream.Deleted)
+		require.Equal(t, 1, deleted.Stream.Revision)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/details", nil, nil)
+		require.Equal(t, http.StatusGone, status)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, nil)
+		require.Equal(t, http.StatusGone, status)
+
+		status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+			"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+		}, nil)
+		require.Equal(t, http.StatusGone, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath, nil, &deleted)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, estypes.DeletionSoft, deleted.Stream.Deleted)
+
+		var streams struct {
+			StreamPage estypes.StreamPage `json:"streamPage"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, "/streams/test-stream", nil, &streams)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, streams.StreamPage.Streams, 1)
+		require.Equal(t, estypes.DeletionSoft, streams.StreamPage.Streams[0].Deleted)
+	})
+}
+
+func TestHardDeleteStream(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		status := doRequest(t, webApp, http.MethodPut, streamPath+"/snapshot", map[string]any{
+			"snapshot": estypes.NewEsSnapshot{Revision: 1, State: "state1"},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath, nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var deleted struct {
+			Stream estypes.Stream `json:"stream"`
+		}
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"?mode=hard", nil, &deleted)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, estypes.DeletionHard, deleted.Stream.Deleted)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"?mode=hard", nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath, nil, nil)
+		require.Equal(t, http.StatusGone, status)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/snapshot", nil, nil)
+		require.Equal(t, http.StatusGone, status)
+
+		status = doRequest(t, webApp, http.MethodPut, "/streams/test-stream/"+stream.StreamId.String(), map[string]any{
+			"initialEvent": estypes.NewEsEvent{EventType: "stream-created", Payload: "payload1"},
+		}, nil)
+		require.Equal(t, http.StatusConflict, status)
+	})
+}
+
+func TestHardDeleteStreamDiscardsPayloads(t *testing.T) {
+	blobDir := filepath.Join(t.TempDir(), "blobs")
+	fsBlobStore, err := blobstore.NewFsBlobStore(blobDir)
+	require.NoError(t, err)
+
+	boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{Offload: repo.NewPayloadOffload(fsBlobStore, 64)})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+
+	webApp := webapp.New(boltRepo, zap.NewNop().Sugar())
+
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: strings.Repeat("extracted text ", 100)},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodDelete, streamPath+"?mode=hard", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	blobs, err := os.ReadDir(filepath.Join(blobDir, stream.StreamId.String()))
+	require.NoError(t, err)
+	require.Empty(t, blobs)
+}
+
+func TestTruncateStreamKeepsPayloadsOfLaterEvents(t *testing.T) {
+	blobDir := filepath.Join(t.TempDir(), "blobs")
+	fsBlobStore, err := blobstore.NewFsBlobStore(blobDir)
+	require.NoError(t, err)
+
+	boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{Offload: repo.NewPayloadOffload(fsBlobStore, 64)})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+
+	webApp := webapp.New(boltRepo, zap.NewNop().Sugar())
+
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	largeText := strings.Repeat("extracted text ", 100)
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"events": []estypes.NewEsEvent{
+			{EventType: "something-happened", Payload: largeText},
+			{EventType: "something-happened", Payload: largeText},
+		},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodDelete, streamPath+"/events?before-revision=3", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	blobs, err := os.ReadDir(filepath.Join(blobDir, stream.StreamId.String()))
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+
+	var found struct {
+		Event estypes.Event `json:"event"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events/3", nil, &found)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, largeText, found.Event.Payload)
+}
+
+func TestTruncateStream(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+			"events": []estypes.NewEsEvent{
+				{EventType: "something-happened", Payload: "payload2"},
+				{EventType: "something-happened", Payload: "payload3"},
+				{EventType: "something-happened", Payload: "payload4"},
+			},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"/events?before-revision=1", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"/events?before-revision=5", nil, nil)
+		require.Equal(t, http.StatusConflict, status)
+
+		var truncated struct {
+			Stream estypes.Stream `json:"stream"`
+		}
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"/events?before-revision=3", nil, &truncated)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 4, truncated.Stream.Revision)
+		require.Equal(t, 3, truncated.Stream.TruncatedBefore)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"/events?before-revision=2", nil, nil)
+		require.Equal(t, http.StatusConflict, status)
+
+		var events struct {
+			EventPage estypes.EventPage `json:"eventPage"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 3, events.EventPage.TruncatedBefore)
+		require.Len(t, events.EventPage.Events, 2)
+		require.Equal(t, 3, events.EventPage.Events[0].Revision)
+		require.Equal(t, 4, events.EventPage.Events[1].Revision)
+
+		var laterEvents struct {
+			EventPage estypes.EventPage `json:"eventPage"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events?after-revision=3", nil, &laterEvents)
+		require.Equal(t, http.StatusOK, status)
+		require.Zero(t, laterEvents.EventPage.TruncatedBefore)
+		require.Len(t, laterEvents.EventPage.Events, 1)
+
+		status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/5", map[string]any{
+			"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload5"},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		var details struct {
+			Stream estypes.Stream `json:"stream"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/details", nil, &details)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 5, details.Stream.Revision)
+		require.Equal(t, 3, details.Stream.TruncatedBefore)
+	})
+}
+
+// unfinishedTruncationStore reports streams as truncated without deleting their events,
+// like a DynamoDB truncation interrupted after marking the stream record.
+type unfinishedTruncationStore struct {
+	repo.EsStore
+	truncatedBefore int
+}
+
+func (s unfinishedTruncationStore) GetStream(ctx context.Context, streamId uuid.UUID) (estypes.Stream, error) {
+	stream, err := s.EsStore.GetStream(ctx, streamId)
+	stream.TruncatedBefore = s.truncatedBefore
+
+	return stream, err
+}
+
+func TestUnfinishedTruncationHidesEvents(t *testing.T) {
+	memRepo := memrepo.NewMemRepo()
+	webApp := webapp.New(unfinishedTruncationStore{EsStore: memRepo, truncatedBefore: 2}, zap.NewNop().Sugar())
+
+	stream := createTestStream(t, webApp, "test-stream")
+	streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+	status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events/1", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events/2", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	eventRange := repo.EventRange{}
+	lowest, highest := eventRange.Revisions(estypes.Stream{Revision: 2, TruncatedBefore: 2})
+	require.Equal(t, 2, lowest)
+	require.Equal(t, 2, highest)
+}
+
+func TestStreamMetadata(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		var metadata struct {
+			Metadata estypes.StreamMetadata `json:"metadata"`
+		}
+		status := doRequest(t, webApp, http.MethodGet, streamPath+"/metadata", nil, &metadata)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, estypes.StreamMetadata{}, metadata.Metadata)
+
+		status = doRequest(t, webApp, http.MethodPut, streamPath+"/metadata", map[string]any{
+			"metadata": estypes.StreamMetadata{ExpireEvents: true},
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		newMetadata := estypes.StreamMetadata{
+			MaxCount: 2,
+			Labels:   map[string]string{"device": "sensor-1"},
+			Owner:    "telemetry",
+		}
+		status = doRequest(t, webApp, http.MethodPut, streamPath+"/metadata", map[string]any{
+			"metadata": newMetadata,
+		}, &metadata)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, newMetadata, metadata.Metadata)
+
+		status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+			"events": []estypes.NewEsEvent{
+				{EventType: "something-happened", Payload: "payload2"},
+				{EventType: "something-happened", Payload: "payload3"},
+			},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		var details struct {
+			Stream estypes.Stream `json:"stream"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/details", nil, &details)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 3, details.Stream.Revision)
+		require.Equal(t, &newMetadata, details.Stream.Metadata)
+
+		var events struct {
+			EventPage estypes.EventPage `json:"eventPage"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, events.EventPage.Events, 2)
+		require.Equal(t, 2, events.EventPage.Events[0].Revision)
+		require.Equal(t, 3, events.EventPage.Events[1].Revision)
+	})
+}
+
+func TestBoundedEventReads(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		newEvents := make([]estypes.NewEsEvent, 0, 5)
+		for i := 2; i <= 6; i++ {
+			newEvents = append(newEvents, estypes.NewEsEvent{EventType: "something-happened", Payload: fmt.Sprintf("payload%d", i)})
+		}
+		status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{"events": newEvents}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		page := readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?direction=backward&limit=2")
+		require.Equal(t, []int{6, 5}, eventRevisions(page.Events))
+		require.True(t, page.HasMore)
+		require.Equal(t, 5, page.LastEvaluatedRevision)
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?direction=backward&after-revision=5")
+		require.Equal(t, []int{4, 3, 2, 1}, eventRevisions(page.Events))
+		require.False(t, page.HasMore)
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?direction=backward&after-revision=6&to-revision=4")
+		require.Equal(t, []int{5, 4}, eventRevisions(page.Events))
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?after-revision=1&to-revision=4")
+		require.Equal(t, []int{2, 3, 4}, eventRevisions(page.Events))
+		require.False(t, page.HasMore)
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?after-revision=2&limit=2")
+		require.Equal(t, []int{3, 4}, eventRevisions(page.Events))
+		require.True(t, page.HasMore)
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?after-revision=6")
+		require.Empty(t, page.Events)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events?direction=sideways", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events?after-revision=-1", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events?limit=4294967297", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestGetEvent(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+			"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		var event struct {
+			Event estypes.Event `json:"event"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events/2", nil, &event)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 2, event.Event.Revision)
+		require.Equal(t, "something-happened", event.Event.EventType)
+		require.Equal(t, "payload2", event.Event.Payload)
+
+		for _, revision := range []string{"0", "3"} {
+			status = doRequest(t, webApp, http.MethodGet, streamPath+"/events/"+revision, nil, nil)
+			require.Equal(t, http.StatusNotFound, status)
+		}
+
+		status = doRequest(t, webApp, http.MethodGet, "/streams/other-stream/"+stream.StreamId.String()+"/events/1", nil, nil)
+		require.Equal(t, http.StatusNotFound, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, streamPath+"/events?before-revision=2", nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		status = doRequest(t, webApp, http.MethodGet, streamPath+"/events/1", nil, nil)
+		require.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestFilterEventsByType(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		stream := createTestStream(t, webApp, "test-stream")
+		streamPath := "/streams/test-stream/" + stream.StreamId.String()
+
+		newEvents := []estypes.NewEsEvent{
+			{EventType: "item-added", Payload: "payload2"},
+			{EventType: "item-removed", Payload: "payload3"},
+			{EventType: "item-added", Payload: "payload4"},
+			{EventType: "checked-out", Payload: "payload5"},
+		}
+		status := doRequest(t, webApp, http.MethodPut, streamPath+"/events/2", map[string]any{"events": newEvents}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		page := readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?event-types=item-added")
+		require.Equal(t, []int{2, 4}, eventRevisions(page.Events))
+		require.False(t, page.HasMore)
+		require.Equal(t, 5, page.LastEvaluatedRevision)
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?event-types=item-removed,checked-out")
+		require.Equal(t, []int{3, 5}, eventRevisions(page.Events))
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?event-types=item-removed&event-types=checked-out&direction=backward")
+		require.Equal(t, []int{5, 3}, eventRevisions(page.Events))
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?event-types=checked-out&limit=2")
+		require.Empty(t, page.Events)
+		require.True(t, page.HasMore)
+		require.Equal(t, 2, page.LastEvaluatedRevision)
+
+		page = readEventPage[estypes.EventPage](t, webApp, streamPath+"/events?event-types=checked-out&after-revision=2&limit=3")
+		require.Equal(t, []int{5}, eventRevisions(page.Events))
+	})
+}
+
+func TestGetAllEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		first := createTestStream(t, webApp, "test-stream")
+		second := createTestStream(t, webApp, "other-stream")
+
+		status := doRequest(t, webApp, http.MethodPut, "/streams/test-stream/"+first.StreamId.String()+"/events/2", map[string]any{
+			"events": []estypes.NewEsEvent{
+				{EventType: "something-happened", Payload: "payload2"},
+				{EventType: "something-happened", Payload: "payload3"},
+			},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		status = doRequest(t, webApp, http.MethodPut, "/streams/other-stream/"+second.StreamId.String()+"/events/2", map[string]any{
+			"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+
+		page := readEventPage[estypes.AllEventPage](t, webApp, "/all/events?after-position=0")
+		require.Equal(t, []eventRef{
+			{first.StreamId, 1},
+			{second.StreamId, 1},
+			{first.StreamId, 2},
+			{first.StreamId, 3},
+			{second.StreamId, 2},
+		}, eventRefs(page.Events))
+		require.Equal(t, []int{1, 2, 3, 4, 5}, eventPositions(page.Events))
+		require.False(t, page.HasMore)
+		require.Equal(t, 5, page.LastEvaluatedPosition)
+
+		page = readEventPage[estypes.AllEventPage](t, webApp, "/all/events?after-position=2&limit=2")
+		require.Equal(t, []eventRef{{first.StreamId, 2}, {first.StreamId, 3}}, eventRefs(page.Events))
+		require.Equal(t, []int{3, 4}, eventPositions(page.Events))
+		require.True(t, page.HasMore)
+		require.Equal(t, 4, page.LastEvaluatedPosition)
+
+		page = readEventPage[estypes.AllEventPage](t, webApp, "/all/events?after-position=5")
+		require.Empty(t, page.Events)
+		require.False(t, page.HasMore)
+		require.Equal(t, 5, page.LastEvaluatedPosition)
+
+		status = doRequest(t, webApp, http.MethodDelete, "/streams/test-stream/"+first.StreamId.String()+"?mode=hard", nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		page = readEventPage[estypes.AllEventPage](t, webApp, "/all/events")
+		require.Equal(t, []eventRef{{second.StreamId, 1}, {second.StreamId, 2}}, eventRefs(page.Events))
+		require.Equal(t, []int{2, 5}, eventPositions(page.Events))
+		require.Equal(t, 5, page.LastEvaluatedPosition)
+
+		var event struct {
+			Event estypes.Event `json:"event"`
+		}
+		status = doRequest(t, webApp, http.MethodGet, "/streams/other-stream/"+second.StreamId.String()+"/events/2", nil, &event)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 5, event.Event.Position)
+
+		status = doRequest(t, webApp, http.MethodGet, "/all/events?after-position=-1", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status = doRequest(t, webApp, http.MethodGet, "/all/events?limit=1001", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestGetAllEventsSkipsHiddenEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		limited := createTestStream(t, webApp, "test-stream")
+		truncated := createTestStream(t, webApp, "test-stream")
+		deleted := createTestStream(t, webApp, "test-stream")
+
+		for _, stream := range []estypes.Stream{limited, truncated} {
+			status := doRequest(t, webApp, http.MethodPut, "/streams/test-stream/"+stream.StreamId.String()+"/events/2", map[string]any{
+				"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+			}, nil)
+			require.Equal(t, http.StatusCreated, status)
+		}
+
+		status := doRequest(t, webApp, http.MethodPut, "/streams/test-stream/"+limited.StreamId.String()+"/metadata", map[string]any{
+			"metadata": estypes.StreamMetadata{MaxCount: 1},
+		}, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, "/streams/test-stream/"+truncated.StreamId.String()+"/events?before-revision=2", nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, "/streams/test-stream/"+deleted.StreamId.String(), nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		page := readEventPage[estypes.AllEventPage](t, webApp, "/all/events")
+		require.Equal(t, []eventRef{{limited.StreamId, 2}, {truncated.StreamId, 2}}, eventRefs(page.Events))
+		require.Equal(t, 5, page.LastEvaluatedPosition)
+	})
+}
+
+func TestGetCategoryEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		first := createTestStream(t, webApp, "loan-application")
+		other := createTestStream(t, webApp, "other-stream")
+		second := createTestStream(t, webApp, "loan-application")
+
+		appendEvent := func(streamType string, stream estypes.Stream, revision int) {
+			path := fmt.Sprintf("/streams/%s/%s/events/%d", streamType, stream.StreamId, revision)
+			status := doRequest(t, webApp, http.MethodPut, path, map[string]any{
+				"event": estypes.NewEsEvent{EventType: "something-happened", Payload: fmt.Sprintf("payload%d", revision)},
+			}, nil)
+			require.Equal(t, http.StatusCreated, status)
+		}
+		appendEvent("loan-application", first, 2)
+		appendEvent("other-stream", other, 2)
+		appendEvent("loan-application", second, 2)
+
+		categoryPath := "/categories/loan-application/events"
+
+		page := readEventPage[estypes.CategoryEventPage](t, webApp, categoryPath)
+		require.Equal(t, []eventRef{
+			{first.StreamId, 1},
+			{second.StreamId, 1},
+			{first.StreamId, 2},
+			{second.StreamId, 2},
+		}, eventRefs(page.Events))
+		require.False(t, page.HasMore)
+		require.NotNil(t, page.NextPageKey)
+
+		page = readEventPage[estypes.CategoryEventPage](t, webApp, categoryPath+"?limit=3")
+		require.Len(t, page.Events, 3)
+		require.True(t, page.HasMore)
+
+		page = readEventPage[estypes.CategoryEventPage](t, webApp, categoryPath+"?limit=3&next-page-key="+url.QueryEscape(*page.NextPageKey))
+		require.Equal(t, []eventRef{{second.StreamId, 2}}, eventRefs(page.Events))
+		require.False(t, page.HasMore)
+
+		lastPageKey := *page.NextPageKey
+		appendEvent("loan-application", first, 3)
+
+		page = readEventPage[estypes.CategoryEventPage](t, webApp, categoryPath+"?next-page-key="+url.QueryEscape(lastPageKey))
+		require.Equal(t, []eventRef{{first.StreamId, 3}}, eventRefs(page.Events))
+
+		var firstEvent struct {
+			Event estypes.Event `json:"event"`
+		}
+		status := doRequest(t, webApp, http.MethodGet, "/streams/loan-application/"+first.StreamId.String()+"/events/2", nil, &firstEvent)
+		require.Equal(t, http.StatusOK, status)
+
+		page = readEventPage[estypes.CategoryEventPage](t, webApp, categoryPath+"?created-after="+url.QueryEscape(firstEvent.Event.CreatedAt.Format(time.RFC3339Nano)))
+		require.Equal(t, []eventRef{{first.StreamId, 2}, {second.StreamId, 2}, {first.StreamId, 3}}, eventRefs(page.Events))
+
+		status = doRequest(t, webApp, http.MethodGet, "/categories/loan-application/events?next-page-key=garbage", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status = doRequest(t, webApp, http.MethodGet, "/categories/loan-application/events?created-after=yesterday", nil, nil)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestGetCategoryEventsSkipsHiddenEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		limited := createTestStream(t, webApp, "loan-application")
+		truncated := createTestStream(t, webApp, "loan-application")
+		deleted := createTestStream(t, webApp, "loan-application")
+
+		for _, stream := range []estypes.Stream{limited, truncated} {
+			status := doRequest(t, webApp, http.MethodPut, "/streams/loan-application/"+stream.StreamId.String()+"/events/2", map[string]any{
+				"event": estypes.NewEsEvent{EventType: "something-happened", Payload: "payload2"},
+			}, nil)
+			require.Equal(t, http.StatusCreated, status)
+		}
+
+		status := doRequest(t, webApp, http.MethodPut, "/streams/loan-application/"+limited.StreamId.String()+"/metadata", map[string]any{
+			"metadata": estypes.StreamMetadata{MaxCount: 1},
+		}, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, "/streams/loan-application/"+truncated.StreamId.String()+"/events?before-revision=2", nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		status = doRequest(t, webApp, http.MethodDelete, "/streams/loan-application/"+deleted.StreamId.String(), nil, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		page := readEventPage[estypes.CategoryEventPage](t, webApp, "/categories/loan-application/events")
+		require.Equal(t, []eventRef{{limited.StreamId, 2}, {truncated.StreamId, 2}}, eventRefs(page.Events))
+	})
+}
+
+func TestStreamPageKeys(t *testing.T) {
+	forEachStore(t, func(t *testing.T, webApp *webapp.WebApp) {
+		for range 101 {
+			createTestStream(t, webApp, "test-stream")
+		}
+
+		type streamsResponse struct {
+			StreamPage estypes.StreamPage `json:"streamPage"`
+		}
+
+		var streams streamsResponse
+		status := doRequest(t, webApp, http.MethodGet, "/streams/test-stream", nil, &streams)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, streams.StreamPage.Streams, 100)
+		require.True(t, streams.StreamPage.HasMore)
+		require.NotNil(t, streams.StreamPage.NextPageKey)
+
+		nextPageKey := *streams.StreamPage.NextPageKey
+		require.NotContains(t, nextPageKey, "test-stream")
+		require.NotContains(t, nextPageKey, "|")
+
+		var nextStreams streamsResponse
+		status = doRequest(t, webApp, http.MethodGet, "/streams/test-stream?next-page-key="+nextPageKey, nil, &nextStreams)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, nextStreams.StreamPage.Streams, 1)
+		require.False(t, nextStreams.StreamPage.HasMore)
+
+		tampered := []byte(nextPageKey)
+		tampered[len(tampered)/2] ^= 1
+		last := streams.StreamPage.Streams[99]
+		rawKey := fmt.Sprintf("%s|test-stream|%s", last.StreamId, last.UpdatedAt.Format(time.RFC3339Nano))
+
+		for _, query := range []string{
+			"/streams/test-stream?next-page-key=" + string(tampered),
+			"/streams/test-stream?next-page-key=" + url.QueryEscape(rawKey),
+			"/streams/test-stream?next-page-key=garbage",
+			"/streams/other-stream?next-page-key=" + nextPageKey,
+			"/categories/test-stream/events?next-page-key=" + nextPageKey,
+		} {
+			status = doRequest(t, webApp, http.MethodGet, query, nil, nil)
+			require.Equal(t, http.StatusBadRequest, status, query)
+		}
+	})
+}
+
+func TestStreamTypeRegistry(t *testing.T) {
+	registryPath := filepath.Join(t.TempDir(), "stream-types.json")
+	require.NoError(t, os.WriteFile(registryPath, []byte(`{
+		"streamTypes": [{
+			"streamType": "order",
+			"eventTypes": [
+				{
+					"eventType": "order-placed",
+					"schema": {
+						"type": "object",
+						"required": ["customer", "items"],
+						"properties": {
+							"customer": {"type": "string"},
+							"items": {"type": "array", "items": {"type": "object", "properties": {"price": {"type": "number", "minimum": 0}}}}
+						}
+					}
+				},
+				{"eventType": "order-noted"}
+			]
+		}]
+	}`), 0600))
+
+	streamTypes, err := registry.Load(registryPath)
+	require.NoError(t, err)
+
+	webApp := webapp.New(memrepo.NewMemRepo(), zap.NewNop().Sugar(), webapp.WithRegistry(streamTypes))
+
+	type errorResponse struct {
+		Details eserror.ValidationErrors `json:"details"`
+	}
+	rejected := func(method string, path string, body any) map[string][]string {
+		t.Helper()
+
+		rec := doRequestWithHeader(t, webApp, method, path, http.Header{}, body, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var response errorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+
+		return response.Details.Messages
+	}
+
+	validOrder := `{"customer": "alice", "items": [{"price": 10}]}`
+
+	var created struct {
+		Stream estypes.Stream `json:"stream"`
+	}
+	status := doRequest(t, webApp, http.MethodPost, "/streams/order", map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "order-placed", Payload: validOrder},
+	}, &created)
+	require.Equal(t, http.StatusCreated, status)
+
+	messages := rejected(http.MethodPost, "/streams/invoice", map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "order-placed", Payload: validOrder},
+	})
+	require.Equal(t, map[string][]string{"streamType": {"not registered"}}, messages)
+
+	messages = rejected(http.MethodPut, "/streams/order/"+uuid.NewString(), map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "order-placed", Payload: `{"items": [{"price": -1}]}`},
+	})
+	require.Contains(t, messages, "initialEvent.payload")
+	require.Contains(t, messages, "initialEvent.payload/items/0/price")
+
+	streamPath := "/streams/order/" + created.Stream.StreamId.String() + "/events/2"
+	messages = rejected(http.MethodPut, streamPath, map[string]any{
+		"events": []estypes.NewEsEvent{
+			{EventType: "order-noted", Payload: "any payload"},
+			{EventType: "order-shipped", Payload: "{}"},
+			{EventType: "order-placed", Payload: "not JSON"},
+		},
+	})
+	require.Equal(t, map[string][]string{
+		"events[1].eventType": {"not registered"},
+		"events[2].payload":   {"invalid JSON"},
+	}, messages)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath, map[string]any{
+		"event": estypes.NewEsEvent{EventType: "order-noted", Payload: "any payload"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	var listed struct {
+		StreamTypes []estypes.StreamTypeDefinition `json:"streamTypes"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/stream-types", nil, &listed)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, listed.StreamTypes, 1)
+	require.Len(t, listed.StreamTypes[0].EventTypes, 2)
+	require.NotEmpty(t, listed.StreamTypes[0].EventTypes[0].Schema)
+
+	var single struct {
+		StreamType estypes.StreamTypeDefinition `json:"streamType"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/stream-types/order", nil, &single)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "order", single.StreamType.StreamType)
+
+	status = doRequest(t, webApp, http.MethodGet, "/stream-types/invoice", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+}
+
+func TestUpcasting(t *testing.T) {
+	esRepo := memrepo.NewMemRepo()
+
+	legacyApp := webapp.New(esRepo, zap.NewNop().Sugar())
+	stream := createTestStream(t, legacyApp, "order")
+	streamPath := "/streams/order/" + stream.StreamId.String()
+
+	status := doRequest(t, legacyApp, http.MethodPut, streamPath+"/events/2", map[string]any{
+		"events": []estypes.NewEsEvent{
+			{EventType: "order-placed", Payload: `{"name":"alice"}`},
+			{EventType: "order-placed", Payload: `{"customer":"bob"}`, SchemaVersion: 2},
+		},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	streamTypes, err := registry.New([]estypes.StreamTypeDefinition{{
+		StreamType: "order",
+		EventTypes: []estypes.EventTypeDefinition{{
+			EventType:     "order-placed",
+			SchemaVersion: 3,
+			Schema:        json.RawMessage(`{"type": "object", "required": ["customer", "currency"]}`),
+			Upcasters: []estypes.UpcasterDefinition{{
+				FromVersion: 1,
+				Patch:       json.RawMessage(`[{"op": "move", "from": "/name", "path": "/customer"}]`),
+			}},
+		}, {
+			EventType: "something-happened",
+		}},
+	}})
+	require.NoError(t, err)
+
+	webApp := webapp.New(esRepo, zap.NewNop().Sugar(), webapp.WithRegistry(streamTypes))
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/4", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "order-placed", Payload: `{"customer":"carol","currency":"USD"}`, SchemaVersion: 2},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status = doRequest(t, webApp, http.MethodPut, streamPath+"/events/4", map[string]any{
+		"event": estypes.NewEsEvent{EventType: "order-placed", Payload: `{"customer":"carol","currency":"USD"}`},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, streamPath+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 4)
+
+	upcast := events.EventPage.Events[1:]
+	require.Equal(t, `{"customer":"alice"}`, upcast[0].Payload)
+	require.Equal(t, 2, upcast[0].SchemaVersion)
+	require.Equal(t, `{"customer":"bob"}`, upcast[1].Payload)
+	require.Equal(t, 2, upcast[1].SchemaVersion)
+	require.Equal(t, `{"customer":"carol","currency":"USD"}`, upcast[2].Payload)
+	require.Equal(t, 3, upcast[2].SchemaVersion)
+
+	var allEvents struct {
+		EventPage estypes.AllEventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/all/events", nil, &allEvents)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, allEvents.EventPage.Events, 4)
+	require.Equal(t, "order", allEvents.EventPage.Events[0].StreamType)
+	require.Equal(t, upcast, stripStreamTypes(allEvents.EventPage.Events[1:]))
+
+	server := httptest.NewServer(webApp)
+	t.Cleanup(server.Close)
+
+	addCurrency := func(payload []byte) ([]byte, error) {
+		var order map[string]any
+		err := json.Unmarshal(payload, &order)
+		if err != nil {
+			return nil, err
+		}
+		order["currency"] = "EUR"
+
+		return json.Marshal(order)
+	}
+	client := eshttp.NewClient(server.URL, eshttp.WithUpcaster("order", "order-placed", 2, addCurrency))
+
+	var payloads []string
+	for event, err := range client.GetEvents("order", stream.StreamId, 1) {
+		require.NoError(t, err)
+		require.Equal(t, 3, event.SchemaVersion)
+		payloads = append(payloads, event.Payload)
+	}
+	require.Equal(t, []string{
+		`{"currency":"EUR","customer":"alice"}`,
+		`{"currency":"EUR","customer":"bob"}`,
+		`{"customer":"carol","currency":"USD"}`,
+	}, payloads)
+
+	var allPayloads []string
+	for event, err := range client.GetAllEvents(1) {
+		require.NoError(t, err)
+		require.Equal(t, 3, event.SchemaVersion)
+		allPayloads = append(allPayloads, event.Payload)
+	}
+	require.Equal(t, payloads, allPayloads)
+}
+
+// stripStreamTypes clears StreamType, which only pages of the global log and of a category have.
+func stripStreamTypes(events []estypes.Event) []estypes.Event {
+	stripped := make([]estypes.Event, 0, len(events))
+	for _, event := range events {
+		event.StreamType = ""
+		stripped = append(stripped, event)
+	}
+
+	return stripped
+}
+
+func TestAppendToStream(t *testing.T) {
+	forEachStore(t, testAppendToStream)
+}
+
+func testAppendToStream(t *testing.T, webApp *webapp.WebApp) {
+	type appendResponse struct {
+		Stream        estypes.Stream `json:"stream"`
+		FirstRevision int            `json:"firstRevision"`
+	}
+
+	streamId := uuid.New()
+	eventsPath := "/streams/audit-log/" + streamId.String() + "/events"
+	event := estypes.NewEsEvent{EventType: "something-happened", Payload: "payload"}
+
+	var appended appendResponse
+	status := doRequest(t, webApp, http.MethodPost, eventsPath, map[string]any{
+		"events": []estypes.NewEsEvent{event, event},
+	}, &appended)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 1, appended.FirstRevision)
+	require.Equal(t, 2, appended.Stream.Revision)
+
+	status = doRequest(t, webApp, http.MethodPost, eventsPath+"?expected-revision=2", map[string]any{"event": event}, &appended)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 3, appended.FirstRevision)
+
+	status = doRequest(t, webApp, http.MethodPost, eventsPath+"?expected-revision=2", map[string]any{"event": event}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPost, eventsPath+"?expected-revision=no-stream", map[string]any{"event": event}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPost, eventsPath+"?expected-revision=stream-exists", map[string]any{"event": event}, &appended)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 4, appended.FirstRevision)
+
+	status = doRequest(t, webApp, http.MethodPost, "/streams/other-log/"+streamId.String()+"/events", map[string]any{"event": event}, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	missingPath := "/streams/audit-log/" + uuid.NewString() + "/events"
+	status = doRequest(t, webApp, http.MethodPost, missingPath+"?expected-revision=stream-exists", map[string]any{"event": event}, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	status = doRequest(t, webApp, http.MethodPost, missingPath+"?expected-revision=no-stream", map[string]any{"event": event}, &appended)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 1, appended.Stream.Revision)
+
+	for _, expected := range []string{"0", "latest", "-1"} {
+		status = doRequest(t, webApp, http.MethodPost, eventsPath+"?expected-revision="+expected, map[string]any{"event": event}, nil)
+		require.Equal(t, http.StatusBadRequest, status, expected)
+	}
+
+	// concurrent appends are retried at the next revision, there are fewer writers than attempts
+	const writers = 5
+	statuses := make(chan int, writers)
+	for range writers {
+		go func() {
+			var body bytes.Buffer
+			_ = json.NewEncoder(&body).Encode(map[string]any{"event": event})
+			rec := httptest.NewRecorder()
+			webApp.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, eventsPath, &body))
+			statuses <- rec.Code
+		}()
+	}
+	for range writers {
+		require.Equal(t, http.StatusCreated, <-statuses)
+	}
+
+	var details struct {
+		Stream estypes.Stream `json:"stream"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/streams/audit-log/"+streamId.String()+"/details", nil, &details)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 4+writers, details.Stream.Revision)
+}
+
+func TestTransaction(t *testing.T) {
+	forEachStore(t, testTransaction)
+}
+
+func testTransaction(t *testing.T, webApp *webapp.WebApp) {
+	type transactionResponse struct {
+		Streams []estypes.Stream `json:"streams"`
+	}
+	type conflictResponse struct {
+		Details eserror.ConflictDetails `json:"details"`
+	}
+
+	from := createTestStream(t, webApp, "account")
+	to := createTestStream(t, webApp, "account")
+	withdrawn := estypes.NewEsEvent{EventType: "money-withdrawn", Payload: "10"}
+	deposited := estypes.NewEsEvent{EventType: "money-deposited", Payload: "10"}
+
+	transaction := map[string]any{"streams": []estypes.StreamAppend{
+		{StreamType: "account", StreamId: from.StreamId, ExpectedRevision: 1, Events: []estypes.NewEsEvent{withdrawn}},
+		{StreamType: "account", StreamId: to.StreamId, ExpectedRevision: 1, Events: []estypes.NewEsEvent{deposited, deposited}},
+	}}
+
+	var completed transactionResponse
+	status := doRequest(t, webApp, http.MethodPost, "/transactions", transaction, &completed)
+	require.Equal(t, http.StatusCreated, status)
+	require.Len(t, completed.Streams, 2)
+	require.Equal(t, from.StreamId, completed.Streams[0].StreamId)
+	require.Equal(t, 2, completed.Streams[0].Revision)
+	require.Equal(t, to.StreamId, completed.Streams[1].StreamId)
+	require.Equal(t, 3, completed.Streams[1].Revision)
+
+	var conflict conflictResponse
+	rec := doRequestWithHeader(t, webApp, http.MethodPost, "/transactions", http.Header{}, transaction, nil)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&conflict))
+	require.Len(t, conflict.Details.Streams, 2)
+
+	// only the stale stream is reported, and the other one is left as is
+	rec = doRequestWithHeader(t, webApp, http.MethodPost, "/transactions", http.Header{}, map[string]any{"streams": []estypes.StreamAppend{
+		{StreamType: "account", StreamId: from.StreamId, ExpectedRevision: 2, Events: []estypes.NewEsEvent{withdrawn}},
+		{StreamType: "account", StreamId: to.StreamId, ExpectedRevision: 1, Events: []estypes.NewEsEvent{deposited}},
+	}}, nil)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&conflict))
+	require.Equal(t, []eserror.StreamConflict{{StreamType: "account", StreamId: to.StreamId}}, conflict.Details.Streams)
+
+	var details struct {
+		Stream estypes.Stream `json:"stream"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/streams/account/"+from.StreamId.String()+"/details", nil, &details)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 2, details.Stream.Revision)
+
+	var events struct {
+		EventPage estypes.EventPage `json:"eventPage"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/streams/account/"+to.StreamId.String()+"/events", nil, &events)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, events.EventPage.Events, 3)
+	require.Equal(t, "money-deposited", events.EventPage.Events[2].EventType)
+
+	status = doRequest(t, webApp, http.MethodPost, "/transactions", map[string]any{"streams": []estypes.StreamAppend{
+		{StreamType: "account", StreamId: from.StreamId, ExpectedRevision: 2, Events: []estypes.NewEsEvent{withdrawn}},
+		{StreamType: "account", StreamId: from.StreamId, ExpectedRevision: 2, Events: []estypes.NewEsEvent{withdrawn}},
+	}}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	missingId := uuid.New()
+	var notFound struct {
+		Details eserror.NotFoundDetails `json:"details"`
+	}
+	rec = doRequestWithHeader(t, webApp, http.MethodPost, "/transactions", http.Header{}, map[string]any{"streams": []estypes.StreamAppend{
+		{StreamType: "account", StreamId: from.StreamId, ExpectedRevision: 2, Events: []estypes.NewEsEvent{withdrawn}},
+		{StreamType: "account", StreamId: missingId, ExpectedRevision: 1, Events: []estypes.NewEsEvent{deposited}},
+	}}, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&notFound))
+	require.Equal(t, []eserror.StreamConflict{{StreamType: "account", StreamId: missingId}}, notFound.Details.Streams)
+
+	status = doRequest(t, webApp, http.MethodPost, "/transactions", map[string]any{"streams": []estypes.StreamAppend{}}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	// a retry with the same idempotency key gets the result of the completed transaction
+	transactHeader := http.Header{webapp.IdempotencyKeyHeader: {"transfer-key"}}
+	transfer := map[string]any{"streams": []estypes.StreamAppend{
+		{StreamType: "account", StreamId: from.StreamId, ExpectedRevision: 2, Events: []estypes.NewEsEvent{withdrawn}},
+		{StreamType: "account", StreamId: to.StreamId, ExpectedRevision: 3, Events: []estypes.NewEsEvent{deposited}},
+	}}
+	var transferred, transferReplayed transactionResponse
+	rec = doRequestWithHeader(t, webApp, http.MethodPost, "/transactions", transactHeader, transfer, &transferred)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Empty(t, rec.Header().Get(webapp.IdempotentReplayedHeader))
+
+	rec = doRequestWithHeader(t, webApp, http.MethodPost, "/transactions", transactHeader, transfer, &transferReplayed)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(webapp.IdempotentReplayedHeader))
+	require.Equal(t, transferred.Streams, transferReplayed.Streams)
+
+	status = doRequest(t, webApp, http.MethodGet, "/streams/account/"+from.StreamId.String()+"/details", nil, &details)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 3, details.Stream.Revision)
+}
+
+func TestReservations(t *testing.T) {
+	forEachStore(t, testReservations)
+}
+
+func testReservations(t *testing.T, webApp *webapp.WebApp) {
+	type reservationResponse struct {
+		Reservation estypes.Reservation `json:"reservation"`
+	}
+	type conflictResponse struct {
+		Details eserror.ConflictDetails `json:"details"`
+	}
+
+	email := func(key string) estypes.ReservationKey {
+		return estypes.ReservationKey{Namespace: "account-email", Key: key}
+	}
+	event := estypes.NewEsEvent{EventType: "email-changed", Payload: "payload"}
+
+	var created streamResponse
+	status := doRequest(t, webApp, http.MethodPost, "/streams/account", map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "account-opened", Payload: "payload"},
+		"claim":        []estypes.ReservationKey{email("alice@example.com")},
+	}, &created)
+	require.Equal(t, http.StatusCreated, status)
+	alice := created.Stream
+
+	var found reservationResponse
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/alice@example.com", nil, &found)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, alice.StreamId, found.Reservation.StreamId)
+	require.Equal(t, "account", found.Reservation.StreamType)
+
+	var conflict conflictResponse
+	rec := doRequestWithHeader(t, webApp, http.MethodPost, "/streams/account", http.Header{}, map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "account-opened", Payload: "payload"},
+		"claim":        []estypes.ReservationKey{email("bob@example.com"), email("alice@example.com")},
+	}, nil)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&conflict))
+	require.Equal(t, []eserror.ReservationConflict{{Namespace: "account-email", Key: "alice@example.com"}}, conflict.Details.Reservations)
+
+	// nothing of the failed write is kept
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/bob@example.com", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	bobId := uuid.New()
+	status = doRequest(t, webApp, http.MethodPut, "/streams/account/"+bobId.String(), map[string]any{
+		"initialEvent": estypes.NewEsEvent{EventType: "account-opened", Payload: "payload"},
+		"claim":        []estypes.ReservationKey{email("bob@example.com")},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodPut, "/streams/account/"+alice.StreamId.String()+"/events/2", map[string]any{
+		"event":   event,
+		"claim":   []estypes.ReservationKey{email("alice@example.org")},
+		"release": []estypes.ReservationKey{email("alice@example.com")},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/alice@example.com", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/alice@example.org", nil, &found)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, alice.StreamId, found.Reservation.StreamId)
+
+	// a key is released only by its holder, and claimed again by it
+	bobEventsPath := "/streams/account/" + bobId.String() + "/events"
+	status = doRequest(t, webApp, http.MethodPut, bobEventsPath+"/2", map[string]any{
+		"event":   event,
+		"release": []estypes.ReservationKey{email("alice@example.org")},
+	}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPut, bobEventsPath+"/2", map[string]any{
+		"event": event,
+		"claim": []estypes.ReservationKey{email("bob@example.com")},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	// a taken key is not retried as a revision conflict
+	status = doRequest(t, webApp, http.MethodPost, bobEventsPath, map[string]any{
+		"event": event,
+		"claim": []estypes.ReservationKey{email("alice@example.org")},
+	}, nil)
+	require.Equal(t, http.StatusConflict, status)
+
+	status = doRequest(t, webApp, http.MethodPut, bobEventsPath+"/3", map[string]any{
+		"event": event,
+		"claim": []estypes.ReservationKey{email("bob@example.org"), email("bob@example.org")},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status = doRequest(t, webApp, http.MethodPut, bobEventsPath+"/3", map[string]any{
+		"event": event,
+		"claim": []estypes.ReservationKey{{Namespace: "account-email"}},
+	}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	server := httptest.NewServer(webApp)
+	t.Cleanup(server.Close)
+	client := eshttp.NewClient(server.URL)
+
+	_, err := client.AppendEventsReserving("account", bobId, 3, []estypes.ReservationKey{{Namespace: "account/url", Key: "https://example.com/bob"}}, nil, event)
+	require.NoError(t, err)
+
+	reservation, err := client.GetReservation("account/url", "https://example.com/bob")
+	require.NoError(t, err)
+	require.Equal(t, bobId, reservation.StreamId)
+
+	_, err = client.CreateStreamClaiming("account", event, email("bob@example.com"))
+	esErr := &eshttp.Error{}
+	require.ErrorAs(t, err, &esErr)
+	require.Equal(t, http.StatusConflict, esErr.StatusCode)
+
+	// deleting a stream releases its keys, the released ones stay free
+	status = doRequest(t, webApp, http.MethodDelete, "/streams/account/"+bobId.String(), nil, nil)
+	require.Equal(t, http.StatusOK, status)
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/bob@example.com", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+	_, err = client.GetReservation("account/url", "https://example.com/bob")
+	require.ErrorAs(t, err, &esErr)
+	require.Equal(t, http.StatusNotFound, esErr.StatusCode)
+
+	_, err = client.CreateStreamClaiming("account", event, email("bob@example.com"))
+	require.NoError(t, err)
+
+	status = doRequest(t, webApp, http.MethodDelete, "/streams/account/"+alice.StreamId.String()+"?mode=hard", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/alice@example.org", nil, nil)
+	require.Equal(t, http.StatusNotFound, status)
+	status = doRequest(t, webApp, http.MethodGet, "/reservations/account-email/bob@example.com", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestDebugVarsAreNotServedByDefault(t *testing.T) {
+	webApp := webapp.New(memrepo.NewMemRepo(), zap.NewNop().Sugar())
+
+	rec := doRequestWithHeader(t, webApp, http.MethodGet, "/debug/vars", http.Header{}, nil, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.NotContains(t, rec.Body.String(), "memstats")
+}
+
+// countingKeyProvider counts the data keys requested for new payloads.
+type countingKeyProvider struct {
+	keyprovider.KeyProvider
+	created atomic.Int32
+}
+
+func (p *countingKeyProvider) CreateKey(ctx context.Context, streamId uuid.UUID) (keyprovider.DataKey, error) {
+	p.created.Add(1)
+
+	return p.KeyProvider.CreateKey(ctx, streamId)
+}
+
+func TestPayloadEncryptionKeyIsCachedBetweenWrites(t *testing.T) {
+	fileKeyProvider, err := keyprovider.NewFileKeyProvider(filepath.Join(t.TempDir(), "keys"))
+	require.NoError(t, err)
+	keys := &countingKeyProvider{KeyProvider: fileKeyProvider}
+
+	boltRepo, err := boltrepo.NewBoltRepo(filepath.Join(t.TempDir(), "event-store.db"), repo.PayloadStorage{
+		Encryption: repo.NewPayloadEncryption(keys),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, boltRepo.Close()) })
+
+	webApp := webapp.New(boltRepo, zap.NewNop().Sugar())
+	stream := createTestStream(t, webApp, "test-stream")
+	require.Equal(t, int32(1), keys.created.Load())
+
+	event := estypes.NewEsEvent{EventType: "something-happened", Payload: "payload"}
+	status := doRequest(t, webApp, http.MethodPut, "/streams/test-stream/"+stream.StreamId.String()+"/events/2", map[string]any{
+		"events": []estypes.NewEsEvent{event, event, event, event, event},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, int32(1), keys.created.Load())
+
+	status = doRequest(t, webApp, http.MethodDelete, "/streams/test-stream/"+stream.StreamId.String()+"/payloads", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	status = doRequest(t, webApp, http.MethodPut, "/streams/test-stream/"+stream.StreamId.String()+"/events/7", map[string]any{
+		"event": event,
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, int32(2), keys.created.Load())
+
+	var written struct {
+		Event estypes.Event `json:"event"`
+	}
+	status = doRequest(t, webApp, http.MethodGet, "/streams/test-stream/"+stream.StreamId.String()+"/events/7", nil, &written)
+	require.Equal(t, http.StatusOK, status)
+	require.False(t, written.Event.Shredded)
+	require.Equal(t, "payload", written.Event.Payload)
+}